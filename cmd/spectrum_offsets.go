@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrcook/retroio/spectrum/tzx"
+	"github.com/mrcook/retroio/storage"
+)
+
+var speccyOffsetsCmd = &cobra.Command{
+	Use:   "offsets FILE",
+	Short: "List the block offsets of a ZX Spectrum TZX file",
+	Long: `List the physical layout of a ZX Spectrum emulator TZX file, giving the
+ID, name, file offset and size in bytes of each block.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		filename := args[0]
+
+		if dskType := mediaType(spectrumMediaType, filename); dskType != "tzx" {
+			fmt.Printf("Unsupported media type: '%s'", dskType)
+			return
+		}
+
+		f, err := os.Open(filename)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer f.Close()
+
+		tape := tzx.New(storage.NewReader(f))
+		if err := tape.Read(); err != nil {
+			fmt.Println("Storage read error!")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		tape.DisplayOffsets()
+	},
+}
+
+func init() {
+	speccyOffsetsCmd.Flags().StringVarP(&spectrumMediaType, "media", "m", "", `Media type, default: file extension`)
+	spectrumCmd.AddCommand(speccyOffsetsCmd)
+}
@@ -1,12 +1,68 @@
 package tzx
 
 import (
+	"encoding/binary"
 	"fmt"
 
+	"github.com/pkg/errors"
+
 	"github.com/mrcook/retroio/spectrum/tzx/blocks"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
 )
 
+// ErrUnsupportedBlock is returned when a block ID is unknown or deprecated, and
+// so can not be read. Use the UnknownBlockPolicy reader option to skip these
+// blocks instead.
+type ErrUnsupportedBlock struct {
+	ID         uint8 // ID of the block
+	Offset     int64 // Offset of the block ID byte from the start of the file
+	Deprecated bool  // The ID is a deprecated block, rather than an unknown one
+}
+
+func (e *ErrUnsupportedBlock) Error() string {
+	if e.Deprecated {
+		return fmt.Sprintf("TZX block ID 0x%02X at offset %d is deprecated", e.ID, e.Offset)
+	}
+	return fmt.Sprintf("TZX block ID 0x%02X at offset %d is not supported", e.ID, e.Offset)
+}
+
+// newBlock returns a block from the BlockFactory option, when available,
+// otherwise the default TZX block for the ID.
+func (t TZX) newBlock(id byte) (Block, error) {
+	if t.options.BlockFactory != nil {
+		if block := t.options.BlockFactory(id); block != nil {
+			return block, nil
+		}
+	}
+
+	block, err := newFromBlockID(id)
+	if e, ok := err.(*ErrUnsupportedBlock); ok {
+		e.Offset = t.reader.Offset()
+	}
+	return block, err
+}
+
+// unknownBlock handles a block with an unsupported ID according to the
+// UnknownBlockPolicy. A nil block is returned when the block was skipped.
+func (t TZX) unknownBlock(blockErr error) (Block, error) {
+	switch t.options.UnknownBlockPolicy {
+	case UnknownBlockSkipOneByte:
+		_, err := t.reader.Discard(1)
+		return nil, err
+	case UnknownBlockSkipWithExtensionRule:
+		b, err := t.reader.Peek(5)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read the length of an unknown block")
+		}
+		length := int(binary.LittleEndian.Uint32(b[1:]))
+		_, err = t.reader.Discard(5 + length)
+		return nil, err
+	case UnknownBlockCapture:
+		return &blocks.UnknownBlock{}, nil
+	}
+	return nil, blockErr
+}
+
 // newFromBlockID returns a TZX block based on the type ID byte.
 func newFromBlockID(id byte) (Block, error) {
 	var block Block
@@ -63,10 +119,16 @@ func newFromBlockID(id byte) (Block, error) {
 	case types.GlueBlock:
 		// (90 dec, ASCII Letter 'Z')
 		block = &blocks.GlueBlock{}
-	case types.C64RomType, types.C64TurboData, types.EmulationInfo, types.Snapshot:
-		return nil, fmt.Errorf("TZX block ID 0x%02X is deprecated", id)
+	case types.EmulationInfo:
+		// deprecated, but still found in some older files
+		block = &blocks.EmulationInfo{}
+	case types.Snapshot:
+		// deprecated, but still found in some older files
+		block = &blocks.Snapshot{}
+	case types.C64RomType, types.C64TurboData:
+		return nil, &ErrUnsupportedBlock{ID: id, Deprecated: true}
 	default:
-		return nil, fmt.Errorf("TZX block ID 0x%02X is not supported", id)
+		return nil, &ErrUnsupportedBlock{ID: id}
 	}
 	return block, nil
 }
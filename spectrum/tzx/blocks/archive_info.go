@@ -2,6 +2,8 @@ package blocks
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -24,6 +26,11 @@ type ArchiveInfo struct {
 	Length      uint16 // Length of the whole block (without these two bytes)
 	StringCount uint8  // Number of text strings
 	Strings     []Text // List of text strings
+	Padding     []byte // Any bytes following the text strings, within the block length
+
+	// RawBytes holds the block exactly as read from the tape, including the
+	// ID byte, when the tape was read using the KeepRawBytes option.
+	RawBytes []byte
 }
 
 type Text struct {
@@ -32,18 +39,32 @@ type Text struct {
 	Characters []byte // Text string in ASCII format
 }
 
+// Text identification bytes.
+const (
+	ArchiveTitle     uint8 = 0x00 // Full title
+	ArchivePublisher uint8 = 0x01 // Software house/publisher
+	ArchiveAuthors   uint8 = 0x02 // Author(s)
+	ArchiveYear      uint8 = 0x03 // Year of publication
+	ArchiveLanguage  uint8 = 0x04 // Language
+	ArchiveCategory  uint8 = 0x05 // Game/utility type
+	ArchivePrice     uint8 = 0x06 // Price
+	ArchiveLoader    uint8 = 0x07 // Protection scheme/loader
+	ArchiveOrigin    uint8 = 0x08 // Origin
+	ArchiveComment   uint8 = 0xff // Comment(s)
+)
+
 // Headings for the Text ID's.
 var headings = map[uint8]string{
-	0x00: "Title",     // 00 - Full title
-	0x01: "Publisher", // 01 - Software house/publisher
-	0x02: "Authors",   // 02 - Author(s)
-	0x03: "Year",      // 03 - Year of publication
-	0x04: "Language",  // 04 - Language
-	0x05: "Category",  // 05 - Game/utility type
-	0x06: "Price",     // 06 - Price
-	0x07: "Loader",    // 07 - Protection scheme/loader
-	0x08: "Origin",    // 08 - Origin
-	0xff: "Comment",   // FF - Comment(s)
+	ArchiveTitle:     "Title",
+	ArchivePublisher: "Publisher",
+	ArchiveAuthors:   "Authors",
+	ArchiveYear:      "Year",
+	ArchiveLanguage:  "Language",
+	ArchiveCategory:  "Category",
+	ArchivePrice:     "Price",
+	ArchiveLoader:    "Loader",
+	ArchiveOrigin:    "Origin",
+	ArchiveComment:   "Comment",
 }
 
 // Read the tape and extract the data.
@@ -57,6 +78,7 @@ func (a *ArchiveInfo) Read(reader *storage.Reader) error {
 	a.Length = reader.ReadShort()
 	a.StringCount = reader.ReadByte()
 
+	read := 1 // the string count byte
 	for i := 0; i < int(a.StringCount); i++ {
 		var t Text
 		t.TypeID = reader.ReadByte()
@@ -65,6 +87,15 @@ func (a *ArchiveInfo) Read(reader *storage.Reader) error {
 			t.Characters = append(t.Characters, c)
 		}
 		a.Strings = append(a.Strings, t)
+		read += 2 + int(t.Length)
+	}
+
+	// keep any padding bytes so the block can be written unchanged
+	if read < int(a.Length) {
+		a.Padding = make([]byte, int(a.Length)-read)
+		if _, err := reader.Read(a.Padding); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -77,31 +108,186 @@ func (a ArchiveInfo) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (a ArchiveInfo) Name() string {
-	return "Archive Info"
+	return blockName(a.Id(), "Archive Info")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (a ArchiveInfo) Category() types.BlockCategory {
+	return types.CategoryArchive
 }
 
 func (a ArchiveInfo) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (a ArchiveInfo) PauseMs() uint16 {
+	return 0
+}
+
+// Field returns the first text string with the given identification byte,
+// or an empty string if the tape does not contain one.
+func (a ArchiveInfo) Field(id uint8) string {
+	for _, t := range a.Strings {
+		if t.TypeID == id {
+			return t.String()
+		}
+	}
+	return ""
+}
+
+// Fields returns all text strings with the given identification byte, as more
+// than one entry of the same type (e.g. Comments) is allowed.
+func (a ArchiveInfo) Fields(id uint8) []string {
+	var fields []string
+	for _, t := range a.Strings {
+		if t.TypeID == id {
+			fields = append(fields, t.String())
+		}
+	}
+	return fields
+}
+
+// Map returns the text strings keyed by their identification byte, including
+// any unknown types. Multiple entries of the same type are joined with a
+// newline.
+func (a ArchiveInfo) Map() map[uint8]string {
+	texts := make(map[uint8]string, len(a.Strings))
+	for _, t := range a.Strings {
+		if text, ok := texts[t.TypeID]; ok {
+			texts[t.TypeID] = text + "\n" + t.String()
+		} else {
+			texts[t.TypeID] = t.String()
+		}
+	}
+	return texts
+}
+
+// Title returns the full title of the software.
+func (a ArchiveInfo) Title() string {
+	return a.Field(ArchiveTitle)
+}
+
+// Publisher returns the software house or publisher.
+func (a ArchiveInfo) Publisher() string {
+	return a.Field(ArchivePublisher)
+}
+
+// Authors returns the author(s) of the software.
+func (a ArchiveInfo) Authors() string {
+	return a.Field(ArchiveAuthors)
+}
+
+// Year returns the year of publication.
+func (a ArchiveInfo) Year() string {
+	return a.Field(ArchiveYear)
+}
+
+// Language returns the language of the software.
+func (a ArchiveInfo) Language() string {
+	return a.Field(ArchiveLanguage)
+}
+
+// SoftwareType returns the game or utility type.
+func (a ArchiveInfo) SoftwareType() string {
+	return a.Field(ArchiveCategory)
+}
+
+// Price returns the price, including the currency.
+func (a ArchiveInfo) Price() string {
+	return a.Field(ArchivePrice)
+}
+
+// Loader returns the protection scheme or loader.
+func (a ArchiveInfo) Loader() string {
+	return a.Field(ArchiveLoader)
+}
+
+// Origin returns the origin, e.g. "Original" or "Budget re-release".
+func (a ArchiveInfo) Origin() string {
+	return a.Field(ArchiveOrigin)
+}
+
+// Comments returns all comment strings.
+func (a ArchiveInfo) Comments() []string {
+	return a.Fields(ArchiveComment)
+}
+
+// Heading returns the heading for the text identification byte, e.g. "Title".
+// Unknown types are given as their hex value, e.g. "Unknown (0x09)".
+func (t Text) Heading() string {
+	if heading, ok := headings[t.TypeID]; ok {
+		return heading
+	}
+	return fmt.Sprintf("Unknown (0x%02X)", t.TypeID)
+}
+
+// String returns the characters as a string, decoded from Latin 1.
+func (t Text) String() string {
+	return decodeLatin1(t.Characters)
+}
+
 // String returns a human readable string of the block data
-// Each character is first converted to a Rune so that Latin characters are preserved.
+// The characters are decoded from Latin 1, with newlines replaced by commas.
 func (a ArchiveInfo) String() string {
 	str := ""
 	for _, b := range a.Strings {
-		var runes []rune
-
-		for _, c := range b.Characters {
-			if c == 0x0a || c == 0x0d {
-				// replace newline with comma
-				runes = append(runes, rune(0x2c)) // 44d
-			} else {
-				runes = append(runes, rune(c))
+		text := strings.Map(func(r rune) rune {
+			if r == 0x0a || r == 0x0d {
+				return ',' // replace newline with comma
 			}
-		}
+			return r
+		}, b.String())
 
-		str += fmt.Sprintf("  %-10s: %s\n", headings[b.TypeID], string(runes))
+		str += fmt.Sprintf("  %-10s: %s\n", b.Heading(), text)
 	}
 
 	return str
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte and any padding.
+func (a ArchiveInfo) Size() int {
+	if a.RawBytes != nil {
+		return len(a.RawBytes)
+	}
+	size := 4 + len(a.Padding) // ID, length and text count bytes
+	for _, t := range a.Strings {
+		size += 2 + len(t.Characters)
+	}
+	return size
+}
+
+// Write the block to the writer, in the TZX file format, including any
+// padding. When the block was read with its RawBytes, these are written
+// unchanged.
+func (a ArchiveInfo) Write(w io.Writer) error {
+	if a.RawBytes != nil {
+		_, err := w.Write(a.RawBytes)
+		return err
+	}
+
+	length := 1 + len(a.Padding) // string count byte
+	for _, t := range a.Strings {
+		if err := checkLength("text", len(t.Characters), 0xff); err != nil {
+			return err
+		}
+		length += 2 + len(t.Characters)
+	}
+	if err := checkLength("block", length, 0xffff); err != nil {
+		return err
+	}
+	if err := checkLength("text count", len(a.Strings), 0xff); err != nil {
+		return err
+	}
+
+	if err := writeFields(w, a.Id(), uint16(length), uint8(len(a.Strings))); err != nil {
+		return err
+	}
+	for _, t := range a.Strings {
+		if err := writeFields(w, t.TypeID, uint8(len(t.Characters)), t.Characters); err != nil {
+			return err
+		}
+	}
+	return writeFields(w, a.Padding)
+}
@@ -0,0 +1,39 @@
+package blocks
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestArchiveInfoRepeatedFields(t *testing.T) {
+	raw := []byte{0x32, 0x00, 0x00, 3}
+	raw = append(raw, ArchiveTitle, 5, 'G', 'a', 'm', 'e', 's')
+	raw = append(raw, ArchiveComment, 5, 'f', 'i', 'r', 's', 't')
+	raw = append(raw, ArchiveComment, 6, 's', 'e', 'c', 'o', 'n', 'd')
+	length := len(raw) - 3
+	raw[1], raw[2] = byte(length), byte(length>>8)
+
+	var a ArchiveInfo
+	if err := a.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(a.Strings) != 3 {
+		t.Fatalf("expected 3 text entries, got %d", len(a.Strings))
+	}
+	if got := a.Field(ArchiveComment); got != "first" {
+		t.Errorf("expected the first comment to be 'first', got '%s'", got)
+	}
+	if got := a.Fields(ArchiveComment); !reflect.DeepEqual(got, []string{"first", "second"}) {
+		t.Errorf("expected comments [first second], got %q", got)
+	}
+	if got := a.Field(ArchiveAuthors); got != "" {
+		t.Errorf("expected no authors, got '%s'", got)
+	}
+	if got := a.Fields(ArchiveAuthors); len(got) != 0 {
+		t.Errorf("expected no author entries, got %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -47,12 +48,22 @@ func (c CallSequence) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (c CallSequence) Name() string {
-	return "Call Sequence"
+	return blockName(c.Id(), "Call Sequence")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (c CallSequence) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 func (c CallSequence) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (c CallSequence) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (c CallSequence) String() string {
 	str := fmt.Sprintf("%s\n", c.Name())
@@ -62,6 +73,20 @@ func (c CallSequence) String() string {
 	return str
 }
 
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (c CallSequence) Size() int {
+	return 3 + 2*len(c.Blocks)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (c CallSequence) Write(w io.Writer) error {
+	if err := checkLength("call count", len(c.Blocks), 0xffff); err != nil {
+		return err
+	}
+	return writeFields(w, c.Id(), uint16(len(c.Blocks)), c.Blocks)
+}
+
 // ReturnFromSequence
 // ID: 27h (39d)
 // This block indicates the end of the Called Sequence. The next block played will be the block after
@@ -88,14 +113,35 @@ func (r ReturnFromSequence) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (r ReturnFromSequence) Name() string {
-	return "Return from Sequence"
+	return blockName(r.Id(), "Return from Sequence")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (r ReturnFromSequence) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (r ReturnFromSequence) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (r ReturnFromSequence) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (r ReturnFromSequence) String() string {
 	return r.Name()
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (r ReturnFromSequence) Size() int {
+	return 1
+}
+
+// Write the block to the writer, in the TZX file format.
+func (r ReturnFromSequence) Write(w io.Writer) error {
+	return writeFields(w, r.Id())
+}
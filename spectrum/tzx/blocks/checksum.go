@@ -0,0 +1,21 @@
+package blocks
+
+// checksum returns the XOR of the flag byte and all data bytes, as used
+// for the parity byte at the end of a tape data block.
+func checksum(flag uint8, data []byte) uint8 {
+	sum := flag
+	for _, b := range data {
+		sum ^= b
+	}
+	return sum
+}
+
+// checksumValid reports whether the last byte of the data is the checksum
+// of the flag (first byte) and the bytes between them. At least a flag and
+// a checksum byte are needed, so shorter data is reported as invalid.
+func checksumValid(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	return checksum(data[0], data[1:len(data)-1]) == data[len(data)-1]
+}
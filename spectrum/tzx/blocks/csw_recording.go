@@ -1,7 +1,14 @@
 package blocks
 
 import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -15,13 +22,21 @@ type CswRecording struct {
 	BlockID          types.BlockType
 	Length           uint32  // Block length (without these four bytes)
 	Pause            uint16  // Pause after this block (in ms).
-	SampleRate       uint16  // Sampling rate
-	SampleSpareByte  uint8   // NOTE: `SampleRate` above uses only 2-bytes for value but specification says 3-bytes, so this is for the spare.
-	CompressionType  uint8   // Compression type: RLE, Z-RLE
+	SampleRate       uint32  // Sampling rate, stored on tape as 3-bytes
+	CompressionType  uint8   // Compression type: 0x01=RLE, 0x02=Z-RLE
 	StoredPulseCount uint32  // Number of stored pulses (after decompression, for validation purposes)
 	Data             []uint8 // CSW data, encoded according to the CSW file format specification.
 }
 
+// CSW compression types.
+var cswCompressionTypes = map[uint8]string{
+	0x01: "RLE",
+	0x02: "Z-RLE",
+}
+
+// Number of bytes used by the fields between the block length and the CSW data.
+const cswRecordingFieldsLength = 10
+
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (c *CswRecording) Read(reader *storage.Reader) error {
@@ -31,16 +46,24 @@ func (c *CswRecording) Read(reader *storage.Reader) error {
 	}
 
 	c.Length = reader.ReadLong()
+	if c.Length < cswRecordingFieldsLength {
+		return fmt.Errorf("invalid block length, expected at least %d bytes, got %d", cswRecordingFieldsLength, c.Length)
+	}
+
 	c.Pause = reader.ReadShort()
-	c.SampleRate = reader.ReadShort()
-	c.SampleSpareByte = reader.ReadByte()
+
+	var sampleRate [3]uint8
+	copy(sampleRate[:], reader.ReadBytes(3))
+	c.SampleRate = reader.Bytes3ToLong(sampleRate)
+
 	c.CompressionType = reader.ReadByte()
 	c.StoredPulseCount = reader.ReadLong()
 
-	c.Data = make([]byte, c.Length)
-	if _, err := reader.Read(c.Data); err != nil {
+	data, err := readLength(reader, uint64(c.Length-cswRecordingFieldsLength))
+	if err != nil {
 		return err
 	}
+	c.Data = data
 
 	return nil
 }
@@ -52,20 +75,101 @@ func (c CswRecording) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (c CswRecording) Name() string {
-	return "CSW Recording"
+	return blockName(c.Id(), "CSW Recording")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (c CswRecording) Category() types.BlockCategory {
+	return types.CategoryAudio
 }
 
 func (c CswRecording) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (c CswRecording) PauseMs() uint16 {
+	return c.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (c *CswRecording) SetPause(ms uint16) {
+	c.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (c CswRecording) String() string {
-	str := fmt.Sprintf("%s\n", c.Name())
-	str += fmt.Sprintf(" - Pause (ms.): %d\n", c.Pause)
-	str += fmt.Sprintf(" - Sample Rate: %d\n", c.SampleRate)
-	str += fmt.Sprintf(" - Compression: %d\n", c.CompressionType)
-	str += fmt.Sprintf(" - Pulse Count: %d\n", c.StoredPulseCount)
+	compression, ok := cswCompressionTypes[c.CompressionType]
+	if !ok {
+		compression = fmt.Sprintf("unknown (0x%02X)", c.CompressionType)
+	}
+	return fmt.Sprintf(
+		"%-19s : %d Hz, %s compression, %d pulses, pause for %d ms.",
+		c.Name(), c.SampleRate, compression, c.StoredPulseCount, c.Pause,
+	)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (c CswRecording) Size() int {
+	return 5 + cswRecordingFieldsLength + len(c.Data)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (c CswRecording) Write(w io.Writer) error {
+	sampleRate, err := longTo3Bytes(int(c.SampleRate))
+	if err != nil {
+		return err
+	}
+	length := cswRecordingFieldsLength + uint32(len(c.Data))
+	return writeFields(w, c.Id(), length, c.Pause, sampleRate, c.CompressionType, c.StoredPulseCount, c.Data)
+}
+
+// Samples returns the RLE encoded pulse data, decompressing it first when the
+// Z-RLE compression type is used.
+func (c CswRecording) Samples() ([]byte, error) {
+	switch c.CompressionType {
+	case 0x01:
+		return c.Data, nil
+	case 0x02:
+		z, err := zlib.NewReader(bytes.NewReader(c.Data))
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decompress CSW data")
+		}
+		defer z.Close()
+
+		data, err := ioutil.ReadAll(z)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to decompress CSW data")
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown CSW compression type: 0x%02x", c.CompressionType)
+	}
+}
+
+// Pulses returns the length of each pulse, as a number of samples at the
+// block sample rate. Each RLE pulse is stored as a single byte, unless it
+// is longer than 255 samples, in which case a zero byte is followed by
+// the length as a DWORD.
+func (c CswRecording) Pulses() ([]uint32, error) {
+	data, err := c.Samples()
+	if err != nil {
+		return nil, err
+	}
+
+	var pulses []uint32
+	for i := 0; i < len(data); i++ {
+		if data[i] != 0 {
+			pulses = append(pulses, uint32(data[i]))
+			continue
+		}
+		if i+4 >= len(data) {
+			return nil, fmt.Errorf("CSW data ends within a long pulse at byte %d", i)
+		}
+		pulses = append(pulses, binary.LittleEndian.Uint32(data[i+1:i+5]))
+		i += 4
+	}
 
-	return str
+	return pulses, nil
 }
@@ -0,0 +1,72 @@
+package blocks
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestCswRecordingRead(t *testing.T) {
+	raw := []byte{
+		0x18,
+		0x11, 0x00, 0x00, 0x00, // block length
+		0xf4, 0x01, // pause of 500 ms
+		0x44, 0xac, 0x00, // sample rate of 44100 Hz
+		0x01,                   // RLE compression
+		0x03, 0x00, 0x00, 0x00, // stored pulses
+		0x0a, 0x14, 0x00, 0x2c, 0x01, 0x00, 0x00,
+	}
+
+	var c CswRecording
+	if err := c.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c.Length != 17 {
+		t.Errorf("expected a block length of 17, got %d", c.Length)
+	}
+	if c.Pause != 500 {
+		t.Errorf("expected a pause of 500 ms, got %d", c.Pause)
+	}
+	if c.SampleRate != 44100 {
+		t.Errorf("expected a sample rate of 44100 Hz, got %d", c.SampleRate)
+	}
+	if c.CompressionType != 0x01 {
+		t.Errorf("expected RLE compression, got 0x%02x", c.CompressionType)
+	}
+	if c.StoredPulseCount != 3 {
+		t.Errorf("expected 3 stored pulses, got %d", c.StoredPulseCount)
+	}
+
+	pulses, err := c.Pulses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(pulses, []uint32{10, 20, 300}) {
+		t.Errorf("expected pulses of [10 20 300], got %v", pulses)
+	}
+
+	want := "CSW Recording       : 44100 Hz, RLE compression, 3 pulses, pause for 500 ms."
+	if c.String() != want {
+		t.Errorf("expected '%s', got '%s'", want, c.String())
+	}
+
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("expected the block to be written unchanged\nexpected: % x\ngot:      % x", raw, buf.Bytes())
+	}
+}
+
+func TestCswRecordingLengthTooShort(t *testing.T) {
+	raw := []byte{0x18, 0x09, 0x00, 0x00, 0x00, 0xf4, 0x01, 0x44, 0xac, 0x00, 0x01, 0x00, 0x00, 0x00}
+
+	var c CswRecording
+	if err := c.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+		t.Error("expected an error for a block length shorter than the CSW fields")
+	}
+}
@@ -2,6 +2,8 @@ package blocks
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -15,7 +17,7 @@ import (
 // poke data.
 type CustomInfo struct {
 	BlockID        types.BlockType
-	Identification [10]byte // Identification string (in ASCII)
+	Identification [16]byte // Identification string (in ASCII)
 	Length         uint32   // Length of the custom info
 	Info           []uint8  // Custom info
 }
@@ -28,15 +30,17 @@ func (c *CustomInfo) Read(reader *storage.Reader) error {
 		return fmt.Errorf("expected block ID 0x%02x, got 0x%02x", c.Id(), c.BlockID)
 	}
 
-	for i, b := range reader.ReadBytes(10) {
+	for i, b := range reader.ReadBytes(16) {
 		c.Identification[i] = b
 	}
 
 	c.Length = reader.ReadLong()
 
-	for _, b := range reader.ReadBytes(int(c.Length)) {
-		c.Info = append(c.Info, b)
+	info, err := readLength(reader, uint64(c.Length))
+	if err != nil {
+		return fmt.Errorf("custom info does not match the block length of %d bytes: %v", c.Length, err)
 	}
+	c.Info = info
 
 	return nil
 }
@@ -48,14 +52,41 @@ func (c CustomInfo) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (c CustomInfo) Name() string {
-	return "Custom Info"
+	return blockName(c.Id(), "Custom Info")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (c CustomInfo) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (c CustomInfo) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (c CustomInfo) PauseMs() uint16 {
+	return 0
+}
+
+// Label returns the identification string, e.g. "POKEs" or "Instructions",
+// with the trailing spaces and nulls removed.
+func (c CustomInfo) Label() string {
+	return strings.TrimRight(decodeLatin1(c.Identification[:]), " \x00")
+}
+
 // String returns a human readable string of the block data
 func (c CustomInfo) String() string {
-	return fmt.Sprintf("%-19s : %s - %s", c.Name(), c.Identification, c.Info)
+	return fmt.Sprintf("%-19s : %s - %d bytes", c.Name(), c.Label(), len(c.Info))
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (c CustomInfo) Size() int {
+	return 1 + len(c.Identification) + 4 + len(c.Info)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (c CustomInfo) Write(w io.Writer) error {
+	return writeFields(w, c.Id(), c.Identification, uint32(len(c.Info)), c.Info)
 }
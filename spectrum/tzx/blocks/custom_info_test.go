@@ -0,0 +1,60 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+// customInfoBlock returns a Custom Info block with the given info bytes,
+// and a declared length that may differ from them.
+func customInfoBlock(length uint32, info []byte) []byte {
+	raw := []byte{0x35}
+	raw = append(raw, []byte("POKEs           ")...)
+	raw = append(raw, byte(length), byte(length>>8), byte(length>>16), byte(length>>24))
+	return append(raw, info...)
+}
+
+func TestCustomInfoRead(t *testing.T) {
+	raw := customInfoBlock(3, []byte{1, 2, 3})
+
+	var c CustomInfo
+	if err := c.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(c.Info, []byte{1, 2, 3}) {
+		t.Errorf("expected info of 01 02 03, got % x", c.Info)
+	}
+	if c.Size() != len(raw) {
+		t.Errorf("expected a size of %d bytes, got %d", len(raw), c.Size())
+	}
+}
+
+func TestCustomInfoLengthTooLong(t *testing.T) {
+	raw := customInfoBlock(4, []byte{1, 2, 3})
+
+	t.Run("known file size", func(t *testing.T) {
+		reader := storage.NewReader(bytes.NewReader(raw))
+		reader.FileSize = len(raw)
+
+		var c CustomInfo
+		if err := c.Read(reader); err == nil {
+			t.Error("expected an error for a length running past the end of the file")
+		}
+	})
+
+	t.Run("unknown file size", func(t *testing.T) {
+		var c CustomInfo
+		if err := c.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+			t.Error("expected an error for a block shorter than its length")
+		}
+	})
+
+	t.Run("huge length", func(t *testing.T) {
+		var c CustomInfo
+		if err := c.Read(storage.NewReader(bytes.NewReader(customInfoBlock(0xffffffff, []byte{1, 2, 3})))); err == nil {
+			t.Error("expected an error for a block shorter than its length")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -44,10 +45,13 @@ func (d *DirectRecording) Read(reader *storage.Reader) error {
 
 	d.displayLength = reader.Bytes3ToLong(d.Length)
 
-	// TODO: read this as TAP data.
-	d.Data = make([]byte, d.displayLength)
-	_, err := reader.Read(d.Data)
-	return err
+	data, err := readLength(reader, uint64(d.displayLength))
+	if err != nil {
+		return err
+	}
+	d.Data = data
+
+	return nil
 }
 
 // Id of the block as given in the TZX specification, written as a hexadecimal number.
@@ -57,14 +61,87 @@ func (d DirectRecording) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (d DirectRecording) Name() string {
-	return "Direct Recording"
+	return blockName(d.Id(), "Direct Recording")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (d DirectRecording) Category() types.BlockCategory {
+	return types.CategoryAudio
 }
 
 func (d DirectRecording) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (d DirectRecording) PauseMs() uint16 {
+	return d.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (d *DirectRecording) SetPause(ms uint16) {
+	d.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (d DirectRecording) String() string {
 	return fmt.Sprintf("%-19s : %d T-States, %d bytes", d.Name(), d.TStatesPerSample, d.displayLength)
 }
+
+// SampleCount returns the number of samples stored in the block, taking into
+// account the used bits of the last byte.
+func (d DirectRecording) SampleCount() int {
+	if len(d.Data) == 0 {
+		return 0
+	}
+	usedBits := int(d.UsedBits)
+	if usedBits == 0 || usedBits > 8 {
+		usedBits = 8
+	}
+	return (len(d.Data)-1)*8 + usedBits
+}
+
+// Samples unpacks the data into individual sample levels, MSb first, where
+// true is a high level and false is low. Only the used bits of the last byte
+// are included.
+func (d DirectRecording) Samples() []bool {
+	samples := make([]bool, 0, d.SampleCount())
+
+	for i := 0; i < d.SampleCount(); i++ {
+		b := d.Data[i/8]
+		samples = append(samples, b&(0x80>>uint(i%8)) != 0)
+	}
+
+	return samples
+}
+
+// SampleRate returns the sampling frequency in Hz, derived from the T-states
+// per sample. The preferred values of 79 and 158 T-states are given as their
+// nominal rates of 44100 and 22050 Hz, otherwise the rate is rounded to the
+// nearest Hz. A value of 0 T-states per sample returns 0.
+func (d DirectRecording) SampleRate() int {
+	switch d.TStatesPerSample {
+	case 0:
+		return 0
+	case 79:
+		return 44100
+	case 158:
+		return 22050
+	}
+	return (TStatesPerSecond + int(d.TStatesPerSample)/2) / int(d.TStatesPerSample)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (d DirectRecording) Size() int {
+	return 9 + len(d.Data)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (d DirectRecording) Write(w io.Writer) error {
+	length, err := longTo3Bytes(len(d.Data))
+	if err != nil {
+		return err
+	}
+	return writeFields(w, d.Id(), d.TStatesPerSample, d.Pause, d.UsedBits, length, d.Data)
+}
@@ -0,0 +1,41 @@
+package blocks
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestDirectRecordingUsedBits(t *testing.T) {
+	// 79 T-states per sample, no pause, 3 used bits, and 2 bytes of samples
+	raw := []byte{0x15, 0x4f, 0x00, 0x00, 0x00, 0x03, 0x02, 0x00, 0x00, 0xf0, 0xbf}
+
+	var d DirectRecording
+	if err := d.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.SampleCount() != 11 {
+		t.Errorf("expected 11 samples, got %d", d.SampleCount())
+	}
+
+	// only the first 3 bits of the last byte (101) are played
+	want := []bool{true, true, true, true, false, false, false, false, true, false, true}
+	if got := d.Samples(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected samples %v, got %v", want, got)
+	}
+}
+
+func TestDirectRecordingLengthTooLong(t *testing.T) {
+	raw := []byte{0x15, 0x4f, 0x00, 0x00, 0x00, 0x08, 0xff, 0xff, 0xff, 0xf0}
+
+	reader := storage.NewReader(bytes.NewReader(raw))
+	reader.FileSize = len(raw)
+
+	var d DirectRecording
+	if err := d.Read(reader); err == nil {
+		t.Error("expected an error for a length running past the end of the file")
+	}
+}
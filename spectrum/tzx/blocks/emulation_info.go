@@ -0,0 +1,119 @@
+package blocks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+	"github.com/mrcook/retroio/storage"
+)
+
+// EmulationInfo
+// ID: 34h (52d)
+// This is a special block that would normally be generated only by emulators. For now it
+// contains info on everything I could find that other formats support. Please inform me of any
+// additions/corrections since this is a very important part for emulators.
+// Those bits that are not used should be set to 0 for compatibility with future versions.
+// NOTE: this block has been deprecated since v1.13 of the specification.
+type EmulationInfo struct {
+	BlockID            types.BlockType
+	Flags              uint16   // General emulation flags
+	RefreshDelay       uint8    // Screen refresh delay 1-255 (interrupts between refreshes)
+	InterruptFrequency uint16   // Interrupt frequency in Hz (1-...)
+	Reserved           [3]uint8 // Reserved for future expansion
+}
+
+// Descriptions for each of the general emulation flag bits.
+var emulationFlags = []string{
+	"R register emulation",             // bit 0
+	"LDIR emulation",                   // bit 1
+	"high resolution colour emulation", // bit 2
+	"",                                 // bit 3, video synchronisation, see below
+	"",                                 // bit 4, video synchronisation, see below
+	"fast loading when ROM load routine used",   // bit 5
+	"border emulation",                          // bit 6
+	"screen refreshing",                         // bit 7
+	"start playing the tape immediately",        // bit 8
+	"auto type LOAD\"\" or press ENTER in 128K", // bit 9
+}
+
+// Read the tape and extract the data.
+// It is expected that the tape pointer is at the correct position for reading.
+func (e *EmulationInfo) Read(reader *storage.Reader) error {
+	e.BlockID = types.BlockType(reader.ReadByte())
+	if e.BlockID != e.Id() {
+		return fmt.Errorf("expected block ID 0x%02x, got 0x%02x", e.Id(), e.BlockID)
+	}
+
+	e.Flags = reader.ReadShort()
+	e.RefreshDelay = reader.ReadByte()
+	e.InterruptFrequency = reader.ReadShort()
+	copy(e.Reserved[:], reader.ReadBytes(3))
+
+	return nil
+}
+
+// Id of the block as given in the TZX specification, written as a hexadecimal number.
+func (e EmulationInfo) Id() types.BlockType {
+	return types.EmulationInfo
+}
+
+// Name of the block as given in the TZX specification.
+func (e EmulationInfo) Name() string {
+	return blockName(e.Id(), "Emulation Info")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (e EmulationInfo) Category() types.BlockCategory {
+	return types.CategoryHardware
+}
+
+func (e EmulationInfo) BlockData() tap.Block {
+	return nil
+}
+
+// PauseMs returns 0 as this block has no pause.
+func (e EmulationInfo) PauseMs() uint16 {
+	return 0
+}
+
+// Flag reports whether the given bit (0-15) of the general emulation flags is set.
+func (e EmulationInfo) Flag(bit uint) bool {
+	return e.Flags&(1<<bit) != 0
+}
+
+// VideoSynchronisation returns the video synchronisation setting from bits 3-4
+// of the flags: 1=high, 3=low, 0 and 2=normal.
+func (e EmulationInfo) VideoSynchronisation() string {
+	switch (e.Flags >> 3) & 0x03 {
+	case 1:
+		return "high"
+	case 3:
+		return "low"
+	}
+	return "normal"
+}
+
+// String returns a human readable string of the block data
+func (e EmulationInfo) String() string {
+	str := fmt.Sprintf("%-19s : refresh delay %d, interrupt frequency %d Hz\n", e.Name(), e.RefreshDelay, e.InterruptFrequency)
+	for bit, description := range emulationFlags {
+		if description != "" && e.Flag(uint(bit)) {
+			str += fmt.Sprintf(" - %s\n", description)
+		}
+	}
+	str += fmt.Sprintf(" - video synchronisation: %s\n", e.VideoSynchronisation())
+	return str
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (e EmulationInfo) Size() int {
+	return 9
+}
+
+// Write the block to the writer, in the TZX file format.
+func (e EmulationInfo) Write(w io.Writer) error {
+	return writeFields(w, e.Id(), e.Flags, e.RefreshDelay, e.InterruptFrequency, e.Reserved)
+}
@@ -2,7 +2,7 @@ package blocks
 
 import (
 	"fmt"
-	"log"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -30,6 +30,7 @@ type GeneralizedData struct {
 	PilotStreams []PilotRLE // 0x12+ (2*NPP+1)*ASP - PRLE[TOTP]  Pilot and sync data stream: this field is present only if TOTP>0
 	DataSymbols  []Symbol   // 0x12+ (TOTP>0)*((2*NPP+1)*ASP)+TOTP*3  - SYMDEF[ASD] Data symbols definition table: this field is present only if TOTD>0
 	DataStreams  []uint8    // 0x12+ (TOTP>0)*((2*NPP+1)*ASP)+ TOTP*3+(2*NPD+1)*ASD - BYTE[DS]  Data stream: this field is present only if TOTD>0
+	Padding      []uint8    // Any bytes following the data stream, within the block length
 }
 
 // The alphabet is stored using a table where each symbol is a row of pulses. The number of columns
@@ -50,6 +51,17 @@ type Symbol struct {
 	PulseLengths []uint16 // WORD[MAXP] Array of pulse lengths.
 }
 
+// Pulses returns the pulse lengths of the symbol, up to the first zero-length
+// pulse that terminates the shorter waves.
+func (s Symbol) Pulses() []uint16 {
+	for i, p := range s.PulseLengths {
+		if p == 0 {
+			return s.PulseLengths[:i]
+		}
+	}
+	return s.PulseLengths
+}
+
 // Most commonly, pilot and sync are repetitions of the same pulse, thus they are represented
 // using a very simple RLE encoding structure which stores the symbol and the number of times
 // it must be repeated.
@@ -64,11 +76,131 @@ type PilotRLE struct {
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (g *GeneralizedData) Read(reader *storage.Reader) error {
-	log.Fatal("GeneralizedData is not currently supported - unable to continue.")
+	g.BlockID = types.BlockType(reader.ReadByte())
+	if g.BlockID != g.Id() {
+		return fmt.Errorf("expected block ID 0x%02x, got 0x%02x", g.Id(), g.BlockID)
+	}
+
+	g.Length = reader.ReadLong()
+	if g.Length < generalizedDataFieldsLength {
+		return fmt.Errorf("invalid block length, expected at least %d bytes, got %d", generalizedDataFieldsLength, g.Length)
+	}
+
+	g.Pause = reader.ReadShort()
+	g.TOTP = reader.ReadLong()
+	g.NPP = reader.ReadByte()
+	g.ASP = reader.ReadByte()
+	g.TOTD = reader.ReadLong()
+	g.NPD = reader.ReadByte()
+	g.ASD = reader.ReadByte()
+
+	// check the tables fit in the block before reading them, so a corrupt
+	// count does not try to read a huge amount of data.
+	if size := g.tablesLength(); size > uint64(g.Length-generalizedDataFieldsLength) {
+		return fmt.Errorf("symbol tables need %d bytes, but the block length is only %d bytes", size, g.Length)
+	}
+
+	if g.TOTP > 0 {
+		g.PilotSymbols = readSymbols(reader, alphabetSize(g.ASP), g.NPP)
+		for i := 0; i < int(g.TOTP); i++ {
+			var p PilotRLE
+			p.Symbol = reader.ReadByte()
+			p.RepetitionCount = reader.ReadShort()
+			g.PilotStreams = append(g.PilotStreams, p)
+		}
+	}
+
+	if g.TOTD > 0 {
+		g.DataSymbols = readSymbols(reader, alphabetSize(g.ASD), g.NPD)
+		data, err := readLength(reader, g.dataStreamLength())
+		if err != nil {
+			return err
+		}
+		g.DataStreams = data
+	}
+
+	// keep any padding bytes so the block can be written unchanged
+	if read := generalizedDataFieldsLength + g.tablesLength(); read < uint64(g.Length) {
+		padding, err := readLength(reader, uint64(g.Length)-read)
+		if err != nil {
+			return err
+		}
+		g.Padding = padding
+	}
 
 	return nil
 }
 
+// generalizedDataFieldsLength is the number of bytes, counted in the block
+// length, used by the fixed fields before the symbol tables.
+const generalizedDataFieldsLength = 14
+
+// readSymbols reads a symbol definition table.
+func readSymbols(reader *storage.Reader, count int, maxPulses uint8) []Symbol {
+	symbols := make([]Symbol, count)
+	for i := range symbols {
+		symbols[i].Flags = reader.ReadByte()
+		for j := 0; j < int(maxPulses); j++ {
+			symbols[i].PulseLengths = append(symbols[i].PulseLengths, reader.ReadShort())
+		}
+	}
+	return symbols
+}
+
+// alphabetSize returns the number of symbols in an alphabet table, where 0 means 256.
+func alphabetSize(size uint8) int {
+	if size == 0 {
+		return 256
+	}
+	return int(size)
+}
+
+// SymbolBits returns the number of bits used for each symbol in the data
+// stream, NB = ceiling(Log2(ASD)).
+func (g GeneralizedData) SymbolBits() int {
+	bits := 0
+	for 1<<uint(bits) < alphabetSize(g.ASD) {
+		bits++
+	}
+	return bits
+}
+
+// dataStreamLength returns the number of bytes in the data stream, DS = ceil(NB*TOTD/8).
+func (g GeneralizedData) dataStreamLength() uint64 {
+	return (uint64(g.SymbolBits())*uint64(g.TOTD) + 7) / 8
+}
+
+// tablesLength returns the number of bytes used by the symbol tables and
+// the data stream.
+func (g GeneralizedData) tablesLength() uint64 {
+	var length uint64
+	if g.TOTP > 0 {
+		length += uint64(2*int(g.NPP)+1) * uint64(alphabetSize(g.ASP))
+		length += uint64(g.TOTP) * 3
+	}
+	if g.TOTD > 0 {
+		length += uint64(2*int(g.NPD)+1) * uint64(alphabetSize(g.ASD))
+		length += g.dataStreamLength()
+	}
+	return length
+}
+
+// DataSymbol returns the index of the data symbol at the given position of
+// the data stream, where each symbol uses SymbolBits bits, MSb first.
+func (g GeneralizedData) DataSymbol(index int) int {
+	bits := g.SymbolBits()
+
+	symbol := 0
+	for i := 0; i < bits; i++ {
+		bit := index*bits + i
+		symbol <<= 1
+		if g.DataStreams[bit/8]&(0x80>>uint(bit%8)) != 0 {
+			symbol |= 1
+		}
+	}
+	return symbol
+}
+
 // Id of the block as given in the TZX specification, written as a hexadecimal number.
 func (g GeneralizedData) Id() types.BlockType {
 	return types.GeneralizedData
@@ -76,14 +208,94 @@ func (g GeneralizedData) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (g GeneralizedData) Name() string {
-	return "Generalized Data"
+	return blockName(g.Id(), "Generalized Data")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (g GeneralizedData) Category() types.BlockCategory {
+	return types.CategoryData
 }
 
 func (g GeneralizedData) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (g GeneralizedData) PauseMs() uint16 {
+	return g.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (g *GeneralizedData) SetPause(ms uint16) {
+	g.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (g GeneralizedData) String() string {
-	return fmt.Sprintf("%s", g.Name())
+	return fmt.Sprintf(
+		"%-19s : %d pilot/sync symbols, %d data symbols, pause for %d ms.",
+		g.Name(), g.TOTP, g.TOTD, g.Pause,
+	)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte and any padding.
+func (g GeneralizedData) Size() int {
+	return 5 + generalizedDataFieldsLength + int(g.tablesLength()) + len(g.Padding)
+}
+
+// Write the block to the writer, in the TZX file format, including any padding.
+func (g GeneralizedData) Write(w io.Writer) error {
+	length := generalizedDataFieldsLength + g.tablesLength() + uint64(len(g.Padding))
+	if length > 0xffffffff {
+		return fmt.Errorf("block length of %d is too large, the maximum is %d", length, uint32(0xffffffff))
+	}
+
+	err := writeFields(w, g.Id(), uint32(length), g.Pause, g.TOTP, g.NPP, g.ASP, g.TOTD, g.NPD, g.ASD)
+	if err != nil {
+		return err
+	}
+
+	if g.TOTP > 0 {
+		if err := writeSymbols(w, g.PilotSymbols, alphabetSize(g.ASP), g.NPP); err != nil {
+			return err
+		}
+		if len(g.PilotStreams) != int(g.TOTP) {
+			return fmt.Errorf("expected %d pilot/sync symbols, got %d", g.TOTP, len(g.PilotStreams))
+		}
+		if err := writeFields(w, g.PilotStreams); err != nil {
+			return err
+		}
+	}
+
+	if g.TOTD > 0 {
+		if err := writeSymbols(w, g.DataSymbols, alphabetSize(g.ASD), g.NPD); err != nil {
+			return err
+		}
+		if uint64(len(g.DataStreams)) != g.dataStreamLength() {
+			return fmt.Errorf("expected %d data stream bytes, got %d", g.dataStreamLength(), len(g.DataStreams))
+		}
+		if err := writeFields(w, g.DataStreams); err != nil {
+			return err
+		}
+	}
+
+	return writeFields(w, g.Padding)
+}
+
+// writeSymbols writes a symbol definition table, checking the number of
+// symbols and pulses match the table size.
+func writeSymbols(w io.Writer, symbols []Symbol, count int, maxPulses uint8) error {
+	if len(symbols) != count {
+		return fmt.Errorf("expected %d symbols in the alphabet, got %d", count, len(symbols))
+	}
+	for _, s := range symbols {
+		if len(s.PulseLengths) != int(maxPulses) {
+			return fmt.Errorf("expected %d pulses for each symbol, got %d", maxPulses, len(s.PulseLengths))
+		}
+		if err := writeFields(w, s.Flags, s.PulseLengths); err != nil {
+			return err
+		}
+	}
+	return nil
 }
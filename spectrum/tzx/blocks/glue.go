@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -21,6 +22,17 @@ type GlueBlock struct {
 	Value   [9]byte // Value: { "XTape!",0x1A,MajR,MinR } Just skip these 9 bytes and you will end up on the next ID.
 }
 
+// glueSignature is the start of the Value of a valid glue block.
+const glueSignature = "XTape!\x1a"
+
+// NewGlueBlock returns a glue block for appending a TZX file of the given revision.
+func NewGlueBlock(major, minor uint8) *GlueBlock {
+	g := &GlueBlock{BlockID: types.GlueBlock}
+	copy(g.Value[:], glueSignature)
+	g.Value[7], g.Value[8] = major, minor
+	return g
+}
+
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (g *GlueBlock) Read(reader *storage.Reader) error {
@@ -43,14 +55,50 @@ func (g GlueBlock) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (g GlueBlock) Name() string {
-	return "Glue Block"
+	return blockName(g.Id(), "Glue Block")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (g GlueBlock) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (g GlueBlock) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (g GlueBlock) PauseMs() uint16 {
+	return 0
+}
+
+// SignatureValid reports whether the block value starts with the expected
+// `XTape!` signature and end of file marker.
+func (g GlueBlock) SignatureValid() bool {
+	return string(g.Value[:len(glueSignature)]) == glueSignature
+}
+
+// Version returns the TZX revision of the file that was appended to the tape.
+func (g GlueBlock) Version() (major, minor uint8) {
+	return g.Value[7], g.Value[8]
+}
+
 // String returns a human readable string of the block data
 func (g GlueBlock) String() string {
-	return fmt.Sprintf("%s", g.Name())
+	if !g.SignatureValid() {
+		return fmt.Sprintf("%-19s : invalid signature", g.Name())
+	}
+	major, minor := g.Version()
+	return fmt.Sprintf("%-19s : v%d.%02d", g.Name(), major, minor)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (g GlueBlock) Size() int {
+	return 1 + len(g.Value)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (g GlueBlock) Write(w io.Writer) error {
+	return writeFields(w, g.Id(), g.Value)
 }
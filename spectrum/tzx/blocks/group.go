@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -45,16 +46,40 @@ func (g GroupStart) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (g GroupStart) Name() string {
-	return "Group Start"
+	return blockName(g.Id(), "Group Start")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (g GroupStart) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (g GroupStart) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (g GroupStart) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (g GroupStart) String() string {
-	return fmt.Sprintf("%-19s : %s", g.Name(), g.GroupName)
+	return fmt.Sprintf("%-19s : %s", g.Name(), decodeLatin1(g.GroupName))
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (g GroupStart) Size() int {
+	return 2 + len(g.GroupName)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (g GroupStart) Write(w io.Writer) error {
+	if err := checkLength("group name", len(g.GroupName), 0xff); err != nil {
+		return err
+	}
+	return writeFields(w, g.Id(), uint8(len(g.GroupName)), g.GroupName)
 }
 
 // GroupEnd
@@ -81,14 +106,35 @@ func (g GroupEnd) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (g GroupEnd) Name() string {
-	return "Group End"
+	return blockName(g.Id(), "Group End")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (g GroupEnd) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (g GroupEnd) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (g GroupEnd) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (g GroupEnd) String() string {
 	return fmt.Sprintf("%s", g.Name())
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (g GroupEnd) Size() int {
+	return 1
+}
+
+// Write the block to the writer, in the TZX file format.
+func (g GroupEnd) Write(w io.Writer) error {
+	return writeFields(w, g.Id())
+}
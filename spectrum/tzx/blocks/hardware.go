@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -60,13 +61,23 @@ func (h HardwareType) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (h HardwareType) Name() string {
-	return "Hardware"
+	return blockName(h.Id(), "Hardware")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (h HardwareType) Category() types.BlockCategory {
+	return types.CategoryHardware
 }
 
 func (h HardwareType) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (h HardwareType) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (h HardwareType) String() string {
 	str := fmt.Sprintf("%s:\n", h.Name())
@@ -78,6 +89,37 @@ func (h HardwareType) String() string {
 	return str
 }
 
+// Descriptions returns a readable sentence for each of the machines and
+// hardware, such as "Runs on ZX Spectrum 128k +2 (grey case)". Hardware
+// not found in the reference tables is described by its type and ID.
+func (h HardwareType) Descriptions() []string {
+	var list []string
+	for _, m := range h.Machines {
+		name, ok := hardwareReferenceIDs[m.Type][m.Id]
+		if !ok {
+			name = fmt.Sprintf("unknown hardware (type %02X, ID %02X)", m.Type, m.Id)
+		} else if m.Type != 0x00 {
+			name = fmt.Sprintf("%s (%s)", name, hardwareReferenceTypes[m.Type])
+		}
+
+		phrase, ok := hardwareInfoPhrases[m.Information]
+		if !ok {
+			phrase = fmt.Sprintf("Unknown compatibility (%02X) with", m.Information)
+		}
+
+		list = append(list, phrase+" "+name)
+	}
+	return list
+}
+
+// Short phrases for the hardware information, used to build the descriptions.
+var hardwareInfoPhrases = map[uint8]string{
+	0x00: "Runs on",
+	0x01: "Uses",
+	0x02: "Runs on, but doesn't use",
+	0x03: "Doesn't run on",
+}
+
 // Information detailing the relationship between a piece of software and the hardware.
 var hardwareInfoIDs = map[uint8]string{
 	0x00: "The tape RUNS on this machine or with this hardware, but may or may not use the hardware or special features of the machine.",
@@ -298,3 +340,17 @@ var hardwareReferenceIDs = map[uint8]map[uint8]string{
 		0x03: "Lambda Colour",
 	},
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (h HardwareType) Size() int {
+	return 2 + 3*len(h.Machines)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (h HardwareType) Write(w io.Writer) error {
+	if err := checkLength("machine count", len(h.Machines), 0xff); err != nil {
+		return err
+	}
+	return writeFields(w, h.Id(), uint8(len(h.Machines)), h.Machines)
+}
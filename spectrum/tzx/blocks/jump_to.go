@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -42,14 +43,35 @@ func (j JumpTo) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (j JumpTo) Name() string {
-	return "Jump To"
+	return blockName(j.Id(), "Jump To")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (j JumpTo) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (j JumpTo) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (j JumpTo) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (j JumpTo) String() string {
 	return fmt.Sprintf("%-19s : %d", j.Name(), j.Value)
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (j JumpTo) Size() int {
+	return 3
+}
+
+// Write the block to the writer, in the TZX file format.
+func (j JumpTo) Write(w io.Writer) error {
+	return writeFields(w, j.Id(), j.Value)
+}
@@ -0,0 +1,37 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestJumpToSignedOffset(t *testing.T) {
+	tests := []struct {
+		raw  []byte
+		want int16
+	}{
+		{[]byte{0x23, 0xff, 0xff}, -1},
+		{[]byte{0x23, 0xfe, 0xff}, -2},
+		{[]byte{0x23, 0x02, 0x00}, 2},
+	}
+
+	for _, tt := range tests {
+		var j JumpTo
+		if err := j.Read(storage.NewReader(bytes.NewReader(tt.raw))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if j.Value != tt.want {
+			t.Errorf("% x: expected an offset of %d, got %d", tt.raw, tt.want, j.Value)
+		}
+
+		var buf bytes.Buffer
+		if err := j.Write(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), tt.raw) {
+			t.Errorf("expected the block to be written as % x, got % x", tt.raw, buf.Bytes())
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package blocks
+
+// decodeLatin1 converts the ISO 8859-1 (Latin 1) text used by the TZX format
+// to a UTF-8 string. Each byte of Latin 1 maps directly to the Unicode code
+// point of the same value, so characters such as `£` (0xA3) are preserved.
+func decodeLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
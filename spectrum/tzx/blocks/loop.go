@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -38,18 +39,39 @@ func (l LoopStart) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (l LoopStart) Name() string {
-	return "Loop Start"
+	return blockName(l.Id(), "Loop Start")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (l LoopStart) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (l LoopStart) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (l LoopStart) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (l LoopStart) String() string {
 	return fmt.Sprintf("%-19s : %d times", l.Name(), l.RepetitionCount)
 }
 
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (l LoopStart) Size() int {
+	return 3
+}
+
+// Write the block to the writer, in the TZX file format.
+func (l LoopStart) Write(w io.Writer) error {
+	return writeFields(w, l.Id(), l.RepetitionCount)
+}
+
 // LoopEnd
 // ID: 25h (37d)
 // This is the same as BASIC's NEXT statement. It means that the utility should jump back to the
@@ -76,14 +98,35 @@ func (l LoopEnd) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (l LoopEnd) Name() string {
-	return "Loop End"
+	return blockName(l.Id(), "Loop End")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (l LoopEnd) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (l LoopEnd) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (l LoopEnd) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (l LoopEnd) String() string {
 	return fmt.Sprintf("%s", l.Name())
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (l LoopEnd) Size() int {
+	return 1
+}
+
+// Write the block to the writer, in the TZX file format.
+func (l LoopEnd) Write(w io.Writer) error {
+	return writeFields(w, l.Id())
+}
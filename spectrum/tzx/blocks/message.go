@@ -2,6 +2,8 @@ package blocks
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -35,8 +37,9 @@ func (m *Message) Read(reader *storage.Reader) error {
 	m.DisplayTime = reader.ReadByte()
 	m.Length = reader.ReadByte()
 
-	for _, b := range reader.ReadBytes(int(m.Length)) {
-		m.Message = append(m.Message, b)
+	m.Message = make([]byte, m.Length)
+	if _, err := reader.Read(m.Message); err != nil {
+		return fmt.Errorf("message shorter than the text length of %d bytes: %v", m.Length, err)
 	}
 
 	return nil
@@ -49,16 +52,59 @@ func (m Message) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (m Message) Name() string {
-	return "Message"
+	return blockName(m.Id(), "Message")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (m Message) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (m Message) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (m Message) PauseMs() uint16 {
+	return 0
+}
+
+// IsWaitForKey reports whether the message has a display time of zero, which
+// some players use to display the message until a key is pressed.
+func (m Message) IsWaitForKey() bool {
+	return m.DisplayTime == 0
+}
+
+// Lines returns the lines of the message, which are separated by a 0x0D byte.
+// The text is decoded from Latin 1.
+func (m Message) Lines() []string {
+	return strings.Split(decodeLatin1(m.Message), "\r")
+}
+
 // String returns a human readable string of the block data
 func (m Message) String() string {
-	str := fmt.Sprintf("%-19s : display for %d seconds\n", m.Name(), m.DisplayTime)
-	str += fmt.Sprintf(" - Message: %s\n", m.Message)
+	var str string
+	if m.IsWaitForKey() {
+		str = fmt.Sprintf("%-19s : display until a key is pressed\n", m.Name())
+	} else {
+		str = fmt.Sprintf("%-19s : display for %d seconds\n", m.Name(), m.DisplayTime)
+	}
+	for _, line := range m.Lines() {
+		str += fmt.Sprintf(" - Message: %s\n", line)
+	}
 	return str
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (m Message) Size() int {
+	return 3 + len(m.Message)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (m Message) Write(w io.Writer) error {
+	if err := checkLength("message", len(m.Message), 0xff); err != nil {
+		return err
+	}
+	return writeFields(w, m.Id(), m.DisplayTime, uint8(len(m.Message)), m.Message)
+}
@@ -0,0 +1,41 @@
+package blocks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestMessageWaitForKey(t *testing.T) {
+	raw := []byte{0x31, 0x00, 0x0b, 'P', 'r', 'e', 's', 's', 0x0d, 'P', 'L', 'A', 'Y', '!'}
+
+	var m Message
+	if err := m.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.IsWaitForKey() {
+		t.Error("expected a zero display time to wait for a key")
+	}
+	if !strings.Contains(m.String(), "until a key is pressed") {
+		t.Errorf("expected the description to wait for a key, got %q", m.String())
+	}
+	if lines := m.Lines(); len(lines) != 2 || lines[0] != "Press" || lines[1] != "PLAY!" {
+		t.Errorf("expected the lines [Press PLAY!], got %q", lines)
+	}
+
+	m.DisplayTime = 5
+	if m.IsWaitForKey() {
+		t.Error("expected a display time of 5 seconds not to wait for a key")
+	}
+}
+
+func TestMessageLengthOverrun(t *testing.T) {
+	raw := []byte{0x31, 0x00, 0x0a, 'S', 'h', 'o', 'r', 't'}
+
+	var m Message
+	if err := m.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+		t.Error("expected an error for a text length longer than the remaining bytes")
+	}
+}
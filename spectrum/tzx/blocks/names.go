@@ -0,0 +1,29 @@
+package blocks
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// customNames holds any block names that override those given in the TZX specification.
+var customNames map[types.BlockType]string
+
+// SetBlockNames overrides the names returned by the `Name()` method of each
+// block, keyed by block ID, e.g. to display translated names in a UI.
+// Blocks not found in the map will use the names given in the specification.
+// Passing a nil map restores the default names.
+//
+// This is not safe for concurrent use, so should be called before reading any tapes.
+func SetBlockNames(names map[uint8]string) {
+	customNames = make(map[types.BlockType]string, len(names))
+	for id, name := range names {
+		customNames[types.BlockType(id)] = name
+	}
+}
+
+// blockName returns the custom name for the block ID, if set, otherwise the default.
+func blockName(id types.BlockType, defaultName string) string {
+	if name, ok := customNames[id]; ok {
+		return name
+	}
+	return defaultName
+}
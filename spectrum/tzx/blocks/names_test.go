@@ -0,0 +1,28 @@
+package blocks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetBlockNames(t *testing.T) {
+	SetBlockNames(map[uint8]string{0x12: "Tono puro", 0x30: "Descripción"})
+	defer SetBlockNames(nil)
+
+	tone := PureTone{PulseCount: 10, Length: 2168}
+	if !strings.HasPrefix(tone.String(), "Tono puro") {
+		t.Errorf("expected the custom name in %q", tone.String())
+	}
+	text := TextDescription{Description: []byte("hi")}
+	if !strings.HasPrefix(text.String(), "Descripción") {
+		t.Errorf("expected the custom name in %q", text.String())
+	}
+	if name := (PauseTapeCommand{}).Name(); name != "Pause Tape Command" {
+		t.Errorf("expected the default name for a block not in the map, got %q", name)
+	}
+
+	SetBlockNames(nil)
+	if name := tone.Name(); name != "Pure Tone" {
+		t.Errorf("expected the default name after a reset, got %q", name)
+	}
+}
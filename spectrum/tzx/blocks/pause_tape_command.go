@@ -2,6 +2,8 @@ package blocks
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -18,6 +20,30 @@ type PauseTapeCommand struct {
 	Pause   uint16 // Pause duration (ms.)
 }
 
+// NewPause returns a pause block of the given duration in milliseconds.
+// Note that a duration of 0 will STOP THE TAPE.
+func NewPause(ms uint16) *PauseTapeCommand {
+	return &PauseTapeCommand{BlockID: types.PauseTapeCommand, Pause: ms}
+}
+
+// NewPauses returns the pause blocks needed for a gap of the given duration
+// in milliseconds. Durations longer than a single block allows are split
+// across multiple consecutive pause blocks. A duration of 0 returns no blocks.
+func NewPauses(ms uint32) []*PauseTapeCommand {
+	var pauses []*PauseTapeCommand
+
+	for ms > 0 {
+		pause := ms
+		if pause > 0xffff {
+			pause = 0xffff
+		}
+		pauses = append(pauses, NewPause(uint16(pause)))
+		ms -= pause
+	}
+
+	return pauses
+}
+
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (p *PauseTapeCommand) Read(reader *storage.Reader) error {
@@ -38,14 +64,58 @@ func (p PauseTapeCommand) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (p PauseTapeCommand) Name() string {
-	return "Pause Tape Command"
+	return blockName(p.Id(), "Pause Tape Command")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (p PauseTapeCommand) Category() types.BlockCategory {
+	return types.CategoryTiming
 }
 
 func (p PauseTapeCommand) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (p PauseTapeCommand) PauseMs() uint16 {
+	return p.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (p *PauseTapeCommand) SetPause(ms uint16) {
+	p.Pause = ms
+}
+
+// Duration returns the pause as a time.Duration.
+// A pause of 0 is not a zero length pause, but means "STOP THE TAPE"; the
+// tape is stopped until the user restarts it, so no duration can be given
+// and 0 is returned. Callers needing to distinguish the two should first
+// check IsStopTheTape.
+func (p PauseTapeCommand) Duration() time.Duration {
+	return time.Duration(p.Pause) * time.Millisecond
+}
+
+// IsStopTheTape reports whether the block has a pause of 0, which means
+// "STOP THE TAPE" rather than a zero length pause.
+func (p PauseTapeCommand) IsStopTheTape() bool {
+	return p.Pause == 0
+}
+
 // String returns a human readable string of the block data
 func (p PauseTapeCommand) String() string {
+	if p.IsStopTheTape() {
+		return fmt.Sprintf("%-19s : stop the tape", p.Name())
+	}
 	return fmt.Sprintf("%-19s : %d ms.", p.Name(), p.Pause)
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (p PauseTapeCommand) Size() int {
+	return 3
+}
+
+// Write the block to the writer, in the TZX file format.
+func (p PauseTapeCommand) Write(w io.Writer) error {
+	return writeFields(w, p.Id(), p.Pause)
+}
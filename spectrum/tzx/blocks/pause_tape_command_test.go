@@ -0,0 +1,37 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewPauses(t *testing.T) {
+	pauses := NewPauses(70000)
+	if len(pauses) != 2 {
+		t.Fatalf("expected 2 pause blocks, got %d", len(pauses))
+	}
+	if pauses[0].Pause != 0xffff || pauses[1].Pause != 70000-0xffff {
+		t.Errorf("expected pauses of 65535ms and 4465ms, got %dms and %dms", pauses[0].Pause, pauses[1].Pause)
+	}
+
+	for i, p := range pauses {
+		if p.IsStopTheTape() {
+			t.Errorf("pause %d: expected a gap, not STOP THE TAPE", i)
+		}
+		var buf bytes.Buffer
+		if err := p.Write(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []byte{0x20, byte(p.Pause), byte(p.Pause >> 8)}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("pause %d: expected the block to be written as % x, got % x", i, want, buf.Bytes())
+		}
+	}
+
+	if pauses := NewPauses(0); len(pauses) != 0 {
+		t.Errorf("expected no pause blocks for a 0ms gap, got %d", len(pauses))
+	}
+	if pauses := NewPauses(0xffff); len(pauses) != 1 {
+		t.Errorf("expected a single pause block for a 65535ms gap, got %d", len(pauses))
+	}
+}
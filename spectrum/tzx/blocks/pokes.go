@@ -0,0 +1,110 @@
+package blocks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// PokesIdentification is the CustomInfo identification string for POKEs data.
+const PokesIdentification = "POKEs"
+
+// PokeGroup is a single trainer from the POKEs custom info block, which is
+// made up of a description and the list of POKEs that must be applied.
+type PokeGroup struct {
+	Description string
+	Pokes       []Poke
+}
+
+// Poke is a single POKE definition of a trainer.
+type Poke struct {
+	Type     uint8  // Poke type (see the methods below)
+	Address  uint16 // Address to POKE
+	Value    uint8  // Value to POKE
+	Original uint8  // Original value at the address
+}
+
+// Page returns the memory page number (0-7) of the POKE.
+func (p Poke) Page() uint8 {
+	return p.Type & 0x07
+}
+
+// IgnorePage reports whether the page number should be ignored (48K page 5,2,0).
+func (p Poke) IgnorePage() bool {
+	return p.Type&0x08 != 0
+}
+
+// AskForValue reports whether the poke value should be ignored, and the user asked for a value.
+func (p Poke) AskForValue() bool {
+	return p.Type&0x10 != 0
+}
+
+// IgnoreOriginal reports whether the original value should be ignored.
+func (p Poke) IgnoreOriginal() bool {
+	return p.Type&0x20 != 0
+}
+
+// IsPokes reports whether the custom info block contains POKEs data.
+func (c CustomInfo) IsPokes() bool {
+	return c.Label() == PokesIdentification
+}
+
+// Pokes decodes the custom info data as a list of trainers, which is
+// stored in the following format:
+//
+//	BYTE     L  Length of the general description (can be 0)
+//	CHAR[L]     General description
+//	BYTE     T  Number of trainers
+//	TRAINER[T]:
+//	  BYTE     N  Length of the trainer description
+//	  CHAR[N]     Trainer description
+//	  BYTE     P  Number of POKEs in the trainer
+//	  POKE[P]:    BYTE type, WORD address, BYTE value, BYTE original value
+func (c CustomInfo) Pokes() (description string, trainers []PokeGroup, err error) {
+	if !c.IsPokes() {
+		return "", nil, errors.New("custom info block does not contain POKEs")
+	}
+
+	reader := bytes.NewReader(c.Info)
+
+	readText := func() (string, error) {
+		length, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		text := make([]byte, length)
+		if _, err := reader.Read(text); err != nil && length > 0 {
+			return "", err
+		}
+		return string(text), nil
+	}
+
+	if description, err = readText(); err != nil {
+		return "", nil, err
+	}
+
+	trainerCount, err := reader.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for i := 0; i < int(trainerCount); i++ {
+		var trainer PokeGroup
+		if trainer.Description, err = readText(); err != nil {
+			return "", nil, err
+		}
+
+		pokeCount, err := reader.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		trainer.Pokes = make([]Poke, pokeCount)
+		if err := binary.Read(reader, binary.LittleEndian, trainer.Pokes); err != nil {
+			return "", nil, err
+		}
+
+		trainers = append(trainers, trainer)
+	}
+
+	return description, trainers, nil
+}
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -40,9 +41,13 @@ func (p *PureData) Read(reader *storage.Reader) error {
 	p.displayLength = reader.Bytes3ToLong(p.Length)
 
 	// TODO: read this as TAP data.
-	p.DataBlock = make([]byte, p.displayLength)
-	_, err := reader.Read(p.DataBlock)
-	return err
+	data, err := readLength(reader, uint64(p.displayLength))
+	if err != nil {
+		return err
+	}
+	p.DataBlock = data
+
+	return nil
 }
 
 // Id of the block as given in the TZX specification, written as a hexadecimal number.
@@ -52,14 +57,50 @@ func (p PureData) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (p PureData) Name() string {
-	return "Pure Data"
+	return blockName(p.Id(), "Pure Data")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (p PureData) Category() types.BlockCategory {
+	return types.CategoryData
 }
 
 func (p PureData) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (p PureData) PauseMs() uint16 {
+	return p.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (p *PureData) SetPause(ms uint16) {
+	p.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (p PureData) String() string {
 	return fmt.Sprintf("%-19s : %d bytes, pause for %d ms.", p.Name(), p.displayLength, p.Pause)
 }
+
+// DataDurationTStates returns the length of the data bit stream, in T-states.
+func (p PureData) DataDurationTStates() uint32 {
+	return uint32(DataTStates(p.DataBlock, p.UsedBits, p.ZeroBitPulse, p.OneBitPulse))
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (p PureData) Size() int {
+	return 11 + len(p.DataBlock)
+}
+
+// Write the block to the writer, in the TZX file format. The bit pulse
+// lengths are written in the same order as they are read.
+func (p PureData) Write(w io.Writer) error {
+	length, err := longTo3Bytes(len(p.DataBlock))
+	if err != nil {
+		return err
+	}
+	return writeFields(w, p.Id(), p.OneBitPulse, p.ZeroBitPulse, p.UsedBits, p.Pause, length, p.DataBlock)
+}
@@ -0,0 +1,29 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestPureDataLengthTooLong(t *testing.T) {
+	raw := []byte{0x14, 0x57, 0x03, 0xae, 0x06, 0x08, 0x00, 0x00, 0x03, 0x00, 0x01, 0xff, 0x00}
+
+	t.Run("known file size", func(t *testing.T) {
+		reader := storage.NewReader(bytes.NewReader(raw))
+		reader.FileSize = len(raw)
+
+		var p PureData
+		if err := p.Read(reader); err == nil {
+			t.Error("expected an error for a length running past the end of the file")
+		}
+	})
+
+	t.Run("unknown file size", func(t *testing.T) {
+		var p PureData
+		if err := p.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+			t.Error("expected an error for a block shorter than its length")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -39,14 +40,53 @@ func (p PureTone) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (p PureTone) Name() string {
-	return "Pure Tone"
+	return blockName(p.Id(), "Pure Tone")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (p PureTone) Category() types.BlockCategory {
+	return types.CategoryTiming
 }
 
 func (p PureTone) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (p PureTone) PauseMs() uint16 {
+	return 0
+}
+
+// Pulses returns the pulse length repeated for each pulse of the tone.
+// A tone with a pulse count of 0 produces no pulses, so nil is returned.
+func (p PureTone) Pulses() []uint16 {
+	if p.PulseCount == 0 {
+		return nil
+	}
+	pulses := make([]uint16, p.PulseCount)
+	for i := range pulses {
+		pulses[i] = p.Length
+	}
+	return pulses
+}
+
+// TStates returns the total length of the tone in T-states.
+func (p PureTone) TStates() uint32 {
+	return uint32(p.PulseCount) * uint32(p.Length)
+}
+
 // String returns a human readable string of the block data
 func (p PureTone) String() string {
 	return fmt.Sprintf("%-19s : %d pulses of %d T-States", p.Name(), p.PulseCount, p.Length)
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (p PureTone) Size() int {
+	return 5
+}
+
+// Write the block to the writer, in the TZX file format.
+func (p PureTone) Write(w io.Writer) error {
+	return writeFields(w, p.Id(), p.Length, p.PulseCount)
+}
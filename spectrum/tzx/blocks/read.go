@@ -0,0 +1,43 @@
+package blocks
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+// readChunkSize is the largest buffer allocated at a time by readLength.
+const readChunkSize = 64 * 1024
+
+// readLength reads the number of bytes given by a length read from the tape.
+// When the file size is known, a length running past the end of the file is
+// an error. The data is read in chunks so that a corrupt length does not
+// allocate a huge buffer before the read fails.
+func readLength(reader *storage.Reader, length uint64) ([]byte, error) {
+	if reader.FileSize > 0 {
+		if remaining := int64(reader.FileSize) - reader.Offset(); int64(length) > remaining {
+			return nil, fmt.Errorf("declared length of %d bytes runs past the end of the file, only %d bytes remain", length, remaining)
+		}
+	}
+
+	size := length
+	if size > readChunkSize {
+		size = readChunkSize
+	}
+	data := make([]byte, 0, size)
+
+	for uint64(len(data)) < length {
+		size := length - uint64(len(data))
+		if size > readChunkSize {
+			size = readChunkSize
+		}
+		chunk := make([]byte, size)
+		n, err := reader.Read(chunk)
+		data = append(data, chunk[:n]...)
+		if err != nil {
+			return data, err
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,65 @@
+package blocks
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// SpectrumHeader is the standard ZX Spectrum ROM header, which describes the
+// file stored in the data block that follows it.
+type SpectrumHeader struct {
+	Type       uint8    // 0 = Program, 1 = Number array, 2 = Character array, 3 = Code
+	Filename   [10]byte // Loading name of the file, padded with spaces
+	DataLength uint16   // Length of the data in the following block
+	Param1     uint16   // e.g. autostart line of a Program, or start address of Code
+	Param2     uint16   // e.g. start of the variables area of a Program
+}
+
+// Header types as given by the type byte.
+var spectrumHeaderTypes = map[uint8]string{
+	0: "Program",
+	1: "Number array",
+	2: "Character array",
+	3: "Code",
+}
+
+// TypeName returns the name of the header type, e.g. "Program".
+func (h SpectrumHeader) TypeName() string {
+	if name, ok := spectrumHeaderTypes[h.Type]; ok {
+		return name
+	}
+	return "Unknown"
+}
+
+// Name returns the filename with any trailing spaces removed.
+func (h SpectrumHeader) Name() string {
+	return strings.TrimRight(string(h.Filename[:]), " ")
+}
+
+// String returns the type and filename, e.g. "Program: MYGAME".
+func (h SpectrumHeader) String() string {
+	return h.TypeName() + ": " + h.Name()
+}
+
+// ROMHeader decodes the block as a ROM header, which is the case when the
+// block is 19 bytes long (flag, 17 header bytes, and the checksum) with a
+// flag byte of 0x00 and a known header type.
+func (s StandardSpeedData) ROMHeader() (*SpectrumHeader, bool) {
+	data := s.Bytes()
+	if len(data) != 19 || data[0] != 0x00 {
+		return nil, false
+	}
+	if _, ok := spectrumHeaderTypes[data[1]]; !ok {
+		return nil, false
+	}
+
+	h := &SpectrumHeader{
+		Type:       data[1],
+		DataLength: binary.LittleEndian.Uint16(data[12:14]),
+		Param1:     binary.LittleEndian.Uint16(data[14:16]),
+		Param2:     binary.LittleEndian.Uint16(data[16:18]),
+	}
+	copy(h.Filename[:], data[2:12])
+
+	return h, true
+}
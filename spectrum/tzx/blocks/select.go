@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -20,6 +21,7 @@ type Select struct {
 	Length     uint16      // Length of the whole block (without these two bytes)
 	Count      uint8       // Number of selections
 	Selections []Selection // List of selections
+	Padding    []byte      // Any bytes following the selections, within the block length
 }
 
 type Selection struct {
@@ -28,6 +30,12 @@ type Selection struct {
 	Description    []uint8 // Description text (please use single line and max. 30 chars)
 }
 
+// SelectTarget is a selection resolved to the block it loads from.
+type SelectTarget struct {
+	Index       int    // Index of the target block (starting from 0)
+	Description string // Description text of the selection
+}
+
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (s *Select) Read(reader *storage.Reader) error {
@@ -39,16 +47,38 @@ func (s *Select) Read(reader *storage.Reader) error {
 	s.Length = reader.ReadShort()
 	s.Count = reader.ReadByte()
 
+	// the descriptions are validated against the block length so that a
+	// corrupt length byte does not read into the following data.
+	read := 1 // the selection count byte
 	for i := 0; i < int(s.Count); i++ {
+		if read+3 > int(s.Length) {
+			return fmt.Errorf("select entry %d overruns the block length of %d bytes", i+1, s.Length)
+		}
+
 		var selection Selection
 		selection.RelativeOffset = int16(reader.ReadShort())
 		selection.Length = reader.ReadByte()
+		read += 3
+
+		if read+int(selection.Length) > int(s.Length) {
+			return fmt.Errorf("select entry %d description length of %d overruns the block length of %d bytes", i+1, selection.Length, s.Length)
+		}
 		for _, b := range reader.ReadBytes(int(selection.Length)) {
 			selection.Description = append(selection.Description, b)
 		}
+		read += int(selection.Length)
+
 		s.Selections = append(s.Selections, selection)
 	}
 
+	// keep any padding bytes so the block can be written unchanged
+	if read < int(s.Length) {
+		s.Padding = make([]byte, int(s.Length)-read)
+		if _, err := reader.Read(s.Padding); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -59,19 +89,82 @@ func (s Select) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (s Select) Name() string {
-	return "Select"
+	return blockName(s.Id(), "Select")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s Select) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (s Select) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (s Select) PauseMs() uint16 {
+	return 0
+}
+
+// Targets returns each selection resolved to the index of the block it
+// loads from, where `index` is the position of this Select block in the
+// tape (starting from 0). The targets are not checked against the length
+// of the tape, so may point outside it on a corrupt tape.
+func (s Select) Targets(index int) []SelectTarget {
+	var targets []SelectTarget
+	for _, selection := range s.Selections {
+		targets = append(targets, SelectTarget{
+			Index:       index + int(selection.RelativeOffset),
+			Description: decodeLatin1(selection.Description),
+		})
+	}
+	return targets
+}
+
 // String returns a human readable string of the block data
 func (s Select) String() string {
 	str := fmt.Sprintf("%s\n", s.Name())
 	for _, b := range s.Selections {
 		str += fmt.Sprintf("- Offset:      %d\n", b.RelativeOffset)
-		str += fmt.Sprintf("  Description: %s\n", b.Description)
+		str += fmt.Sprintf("  Description: %s\n", decodeLatin1(b.Description))
 	}
 	return str
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte and any padding.
+func (s Select) Size() int {
+	size := 4 + len(s.Padding) // ID, length and selection count bytes
+	for _, selection := range s.Selections {
+		size += 3 + len(selection.Description)
+	}
+	return size
+}
+
+// Write the block to the writer, in the TZX file format, including any padding.
+func (s Select) Write(w io.Writer) error {
+	length := 1 + len(s.Padding) // selection count byte
+	for _, selection := range s.Selections {
+		if err := checkLength("description", len(selection.Description), 0xff); err != nil {
+			return err
+		}
+		length += 3 + len(selection.Description)
+	}
+	if err := checkLength("block", length, 0xffff); err != nil {
+		return err
+	}
+	if err := checkLength("selection count", len(s.Selections), 0xff); err != nil {
+		return err
+	}
+
+	if err := writeFields(w, s.Id(), uint16(length), uint8(len(s.Selections))); err != nil {
+		return err
+	}
+	for _, selection := range s.Selections {
+		err := writeFields(w, selection.RelativeOffset, uint8(len(selection.Description)), selection.Description)
+		if err != nil {
+			return err
+		}
+	}
+	return writeFields(w, s.Padding)
+}
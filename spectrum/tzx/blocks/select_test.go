@@ -0,0 +1,55 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestSelectPaddingRoundTrip(t *testing.T) {
+	raw := []byte{
+		0x28,       // ID
+		0x0c, 0x00, // block length
+		0x02,             // selection count
+		0x01, 0x00, 0x02, // offset 1, description length 2
+		'A', 'B',
+		0x02, 0x00, 0x01, // offset 2, description length 1
+		'C',
+		0x00, 0x00, // padding
+	}
+
+	var s Select
+	if err := s.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Size() != len(raw) {
+		t.Errorf("expected a size of %d bytes, got %d", len(raw), s.Size())
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("expected the written block to match the original\nexpected: % x\ngot:      % x", raw, buf.Bytes())
+	}
+}
+
+func TestSelectDescriptionOverrun(t *testing.T) {
+	raw := []byte{
+		0x28,       // ID
+		0x0c, 0x00, // block length
+		0x02,             // selection count
+		0x01, 0x00, 0x02, // offset 1, description length 2
+		'A', 'B',
+		0x02, 0x00, 0x09, // offset 2, description length 9, overrunning the block
+		'C',
+		0x20, 0x00, 0x00, // the following block, a stop the tape
+	}
+
+	var s Select
+	if err := s.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+		t.Error("expected an error for the second description overrunning the block length")
+	}
+}
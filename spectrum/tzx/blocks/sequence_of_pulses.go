@@ -1,7 +1,9 @@
 package blocks
 
 import (
+	"encoding/binary"
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -28,8 +30,12 @@ func (s *SequenceOfPulses) Read(reader *storage.Reader) error {
 
 	s.Count = reader.ReadByte()
 
+	data := make([]byte, 2*int(s.Count))
+	if n, err := reader.Read(data); err != nil {
+		return fmt.Errorf("expected %d pulse lengths, only %d were read: %v", s.Count, n/2, err)
+	}
 	for i := 0; i < int(s.Count); i++ {
-		s.Lengths = append(s.Lengths, reader.ReadShort())
+		s.Lengths = append(s.Lengths, binary.LittleEndian.Uint16(data[i*2:]))
 	}
 
 	return nil
@@ -42,14 +48,52 @@ func (s SequenceOfPulses) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (s SequenceOfPulses) Name() string {
-	return "Sequence of Pulses"
+	return blockName(s.Id(), "Sequence of Pulses")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s SequenceOfPulses) Category() types.BlockCategory {
+	return types.CategoryTiming
 }
 
 func (s SequenceOfPulses) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (s SequenceOfPulses) PauseMs() uint16 {
+	return 0
+}
+
+// Pulses returns a copy of the pulse lengths, in T-states.
+func (s SequenceOfPulses) Pulses() []uint16 {
+	return append([]uint16(nil), s.Lengths...)
+}
+
+// TStates returns the total length of the pulses in T-states.
+func (s SequenceOfPulses) TStates() uint32 {
+	var tStates uint32
+	for _, length := range s.Lengths {
+		tStates += uint32(length)
+	}
+	return tStates
+}
+
 // String returns a human readable string of the block data
 func (s SequenceOfPulses) String() string {
-	return fmt.Sprintf("%-19s : %d pulses", s.Name(), s.Count)
+	return fmt.Sprintf("%-19s : %d pulses, total %d T-States", s.Name(), len(s.Lengths), s.TStates())
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (s SequenceOfPulses) Size() int {
+	return 2 + 2*len(s.Lengths)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (s SequenceOfPulses) Write(w io.Writer) error {
+	if err := checkLength("pulse count", len(s.Lengths), 0xff); err != nil {
+		return err
+	}
+	return writeFields(w, s.Id(), uint8(len(s.Lengths)), s.Lengths)
 }
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -27,6 +28,10 @@ func (s *SetSignalLevel) Read(reader *storage.Reader) error {
 	}
 
 	s.Length = reader.ReadLong()
+	if s.Length != 1 {
+		return fmt.Errorf("invalid block length, expected 1 byte, got %d", s.Length)
+	}
+
 	s.SignalLevel = reader.ReadByte()
 
 	return nil
@@ -39,14 +44,35 @@ func (s SetSignalLevel) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (s SetSignalLevel) Name() string {
-	return "Set Signal Level"
+	return blockName(s.Id(), "Set Signal Level")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s SetSignalLevel) Category() types.BlockCategory {
+	return types.CategoryTiming
 }
 
 func (s SetSignalLevel) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (s SetSignalLevel) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (s SetSignalLevel) String() string {
 	return fmt.Sprintf("%-19s : signal level: %d", s.Name(), s.SignalLevel)
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (s SetSignalLevel) Size() int {
+	return 6
+}
+
+// Write the block to the writer, in the TZX file format.
+func (s SetSignalLevel) Write(w io.Writer) error {
+	return writeFields(w, s.Id(), uint32(1), s.SignalLevel)
+}
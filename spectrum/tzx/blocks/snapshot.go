@@ -0,0 +1,105 @@
+package blocks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+	"github.com/mrcook/retroio/storage"
+)
+
+// Snapshot
+// ID: 40h (64d)
+// This would enable one to snapshot the game at the start and still have all the tape blocks
+// (level data, etc.) in the same file. Only .Z80 and .SNA snapshots are supported for
+// compatibility reasons!
+// The emulator should take care of that the snapshot is not taken while the actual Tape loading
+// is taking place (which doesn't do much sense). And when an emulator encounters the snapshot
+// block it should load it and then continue with the next block.
+// NOTE: this block has been deprecated since v1.13 of the specification.
+type Snapshot struct {
+	BlockID      types.BlockType
+	SnapshotType uint8    // Snapshot type: 00 = .Z80 format, 01 = .SNA format
+	Length       [3]uint8 // Snapshot length
+	Data         []uint8  // Snapshot itself
+
+	displayLength uint32
+}
+
+// Snapshot formats.
+var snapshotTypes = map[uint8]string{
+	0x00: ".Z80",
+	0x01: ".SNA",
+}
+
+// Read the tape and extract the data.
+// It is expected that the tape pointer is at the correct position for reading.
+func (s *Snapshot) Read(reader *storage.Reader) error {
+	s.BlockID = types.BlockType(reader.ReadByte())
+	if s.BlockID != s.Id() {
+		return fmt.Errorf("expected block ID 0x%02x, got 0x%02x", s.Id(), s.BlockID)
+	}
+
+	s.SnapshotType = reader.ReadByte()
+
+	copy(s.Length[:], reader.ReadBytes(3))
+
+	s.displayLength = reader.Bytes3ToLong(s.Length)
+
+	data, err := readLength(reader, uint64(s.displayLength))
+	if err != nil {
+		return err
+	}
+	s.Data = data
+
+	return nil
+}
+
+// Id of the block as given in the TZX specification, written as a hexadecimal number.
+func (s Snapshot) Id() types.BlockType {
+	return types.Snapshot
+}
+
+// Name of the block as given in the TZX specification.
+func (s Snapshot) Name() string {
+	return blockName(s.Id(), "Snapshot")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s Snapshot) Category() types.BlockCategory {
+	return types.CategoryData
+}
+
+func (s Snapshot) BlockData() tap.Block {
+	return nil
+}
+
+// PauseMs returns 0 as this block has no pause.
+func (s Snapshot) PauseMs() uint16 {
+	return 0
+}
+
+// String returns a human readable string of the block data
+func (s Snapshot) String() string {
+	format, ok := snapshotTypes[s.SnapshotType]
+	if !ok {
+		format = fmt.Sprintf("unknown (0x%02X)", s.SnapshotType)
+	}
+	return fmt.Sprintf("%-19s : %s format, %d bytes", s.Name(), format, s.displayLength)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (s Snapshot) Size() int {
+	return 5 + len(s.Data)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (s Snapshot) Write(w io.Writer) error {
+	length, err := longTo3Bytes(len(s.Data))
+	if err != nil {
+		return err
+	}
+	return writeFields(w, s.Id(), s.SnapshotType, length, s.Data)
+}
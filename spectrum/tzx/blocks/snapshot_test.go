@@ -0,0 +1,48 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestSnapshotRead(t *testing.T) {
+	raw := []byte{0x40, 0x01, 0x04, 0x00, 0x00, 1, 2, 3, 4}
+
+	var s Snapshot
+	if err := s.Read(storage.NewReader(bytes.NewReader(raw))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.SnapshotType != 0x01 {
+		t.Errorf("expected the .SNA snapshot type, got 0x%02x", s.SnapshotType)
+	}
+	if !bytes.Equal(s.Data, []byte{1, 2, 3, 4}) {
+		t.Errorf("expected data of 01 02 03 04, got % x", s.Data)
+	}
+	if s.Size() != len(raw) {
+		t.Errorf("expected a size of %d bytes, got %d", len(raw), s.Size())
+	}
+}
+
+func TestSnapshotLengthTooLong(t *testing.T) {
+	// a 3 byte length of 16MB, followed by only two bytes of data
+	raw := []byte{0x40, 0x00, 0xff, 0xff, 0xff, 1, 2}
+
+	t.Run("known file size", func(t *testing.T) {
+		reader := storage.NewReader(bytes.NewReader(raw))
+		reader.FileSize = len(raw)
+
+		var s Snapshot
+		if err := s.Read(reader); err == nil {
+			t.Error("expected an error for a length running past the end of the file")
+		}
+	})
+
+	t.Run("unknown file size", func(t *testing.T) {
+		var s Snapshot
+		if err := s.Read(storage.NewReader(bytes.NewReader(raw))); err == nil {
+			t.Error("expected an error for a block shorter than its length")
+		}
+	})
+}
@@ -1,11 +1,15 @@
 package blocks
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
 
 	"github.com/pkg/errors"
 
 	"github.com/mrcook/retroio/spectrum/tap"
+	tapblocks "github.com/mrcook/retroio/spectrum/tap/blocks"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
 	"github.com/mrcook/retroio/storage"
 )
@@ -28,6 +32,17 @@ type StandardSpeedData struct {
 	displayLength uint16
 }
 
+// NewStandardSpeedData returns a block for the TAP data block, of the given
+// length, to be followed by a pause of the given duration in milliseconds.
+func NewStandardSpeedData(length uint16, data tap.Block, ms uint16) *StandardSpeedData {
+	return &StandardSpeedData{
+		BlockID:       types.StandardSpeedData,
+		Pause:         ms,
+		DataBlock:     data,
+		displayLength: length,
+	}
+}
+
 // Read the tape and extract the data.
 // It is expected that the tape pointer is at the correct position for reading.
 func (s *StandardSpeedData) Read(reader *storage.Reader) error {
@@ -65,13 +80,28 @@ func (s StandardSpeedData) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (s StandardSpeedData) Name() string {
-	return "Standard Speed Data"
+	return blockName(s.Id(), "Standard Speed Data")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s StandardSpeedData) Category() types.BlockCategory {
+	return types.CategoryData
 }
 
 func (s StandardSpeedData) BlockData() tap.Block {
 	return s.DataBlock
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (s StandardSpeedData) PauseMs() uint16 {
+	return s.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (s *StandardSpeedData) SetPause(ms uint16) {
+	s.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (s StandardSpeedData) String() string {
 	str := fmt.Sprintf("%-19s: %d bytes, pause for %d ms\n", s.Name(), s.displayLength, s.Pause)
@@ -79,3 +109,105 @@ func (s StandardSpeedData) String() string {
 
 	return str
 }
+
+// Bytes returns the flag, data and checksum bytes of the TAP block, as they
+// are stored on the tape, but without the leading length word.
+func (s StandardSpeedData) Bytes() []byte {
+	switch b := s.DataBlock.(type) {
+	case nil:
+		return nil
+	case *tapblocks.Standard:
+		data := []byte{b.Flag}
+		data = append(data, b.Data...)
+		return append(data, b.Checksum)
+	case *tapblocks.Fragment:
+		return b.Data
+	default:
+		// Headers are fixed size structs, with the length word as the first field.
+		var buf bytes.Buffer
+		if err := binary.Write(&buf, binary.LittleEndian, b); err != nil || buf.Len() < 2 {
+			return nil
+		}
+		return buf.Bytes()[2:]
+	}
+}
+
+// PilotPulseCount returns the number of pulses in the pilot tone, which is
+// 8063 for a header (flag byte < 128), and 3223 for a data block.
+func (s StandardSpeedData) PilotPulseCount() int {
+	if data := s.Bytes(); len(data) > 0 && data[0] >= 128 {
+		return StandardDataPilotTone
+	}
+	return StandardHeaderPilotTone
+}
+
+// PilotDurationTStates returns the length of the pilot tone and the two sync
+// pulses, in T-states, using the standard ROM timings.
+func (s StandardSpeedData) PilotDurationTStates() uint32 {
+	duration := uint32(s.PilotPulseCount()) * StandardPilotPulse
+	duration += StandardSyncFirstPulse + StandardSyncSecondPulse
+	return duration
+}
+
+// DataDurationTStates returns the length of the data bit stream, in T-states.
+func (s StandardSpeedData) DataDurationTStates() uint32 {
+	return uint32(DataTStates(s.Bytes(), 8, StandardZeroBitPulse, StandardOneBitPulse))
+}
+
+// Pulses returns the length of each pulse, in T-states, needed to play the
+// block using the ROM timings: the pilot tone, the two sync pulses, then two
+// pulses for each bit of the flag, data and checksum bytes.
+func (s StandardSpeedData) Pulses() []uint16 {
+	pulses := make([]uint16, s.PilotPulseCount(), s.PilotPulseCount()+2)
+	for i := range pulses {
+		pulses[i] = StandardPilotPulse
+	}
+	pulses = append(pulses, StandardSyncFirstPulse, StandardSyncSecondPulse)
+
+	return append(pulses, DataPulses(s.Bytes(), 8, StandardZeroBitPulse, StandardOneBitPulse)...)
+}
+
+// ChecksumValid reports whether the checksum byte matches the XOR of the flag
+// and data bytes. Blocks too short to contain a flag and checksum are invalid.
+func (s StandardSpeedData) ChecksumValid() bool {
+	return checksumValid(s.Bytes())
+}
+
+// SetData replaces the data bytes of the block, keeping the existing flag
+// byte, and recalculates the length and checksum values.
+func (s *StandardSpeedData) SetData(data []byte) error {
+	// flag and checksum bytes are included in the length word
+	if len(data)+2 > 0xffff {
+		return fmt.Errorf("data length of %d bytes is too large for a StandardSpeedData block", len(data))
+	}
+
+	var flag uint8
+	if current := s.Bytes(); len(current) > 0 {
+		flag = current[0]
+	}
+
+	s.DataBlock = &tapblocks.Standard{
+		Length:   uint16(len(data) + 2),
+		Flag:     flag,
+		Data:     data,
+		Checksum: checksum(flag, data),
+	}
+	s.displayLength = uint16(len(data) + 2)
+
+	return nil
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (s StandardSpeedData) Size() int {
+	return 5 + len(s.Bytes())
+}
+
+// Write the block to the writer, in the TZX file format.
+func (s StandardSpeedData) Write(w io.Writer) error {
+	data := s.Bytes()
+	if err := checkLength("data", len(data), 0xffff); err != nil {
+		return err
+	}
+	return writeFields(w, s.Id(), s.Pause, uint16(len(data)), data)
+}
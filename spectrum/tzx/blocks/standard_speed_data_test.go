@@ -0,0 +1,78 @@
+package blocks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+// readStandardBlocks reads the Standard Speed Data blocks following the
+// 10 byte header of the TZX file.
+func readStandardBlocks(t *testing.T, raw []byte) []*StandardSpeedData {
+	t.Helper()
+
+	reader := storage.NewReader(bytes.NewReader(raw[10:]))
+	var list []*StandardSpeedData
+	for {
+		if _, err := reader.PeekByte(); err != nil {
+			break
+		}
+		var s StandardSpeedData
+		if err := s.Read(reader); err != nil {
+			t.Fatalf("unexpected error reading block %d: %v", len(list)+1, err)
+		}
+		list = append(list, &s)
+	}
+	return list
+}
+
+func TestStandardSpeedDataRoundTrip(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/standard_speed_data.tzx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := readStandardBlocks(t, raw)
+	if len(list) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(list))
+	}
+
+	written := append([]byte(nil), raw[:10]...)
+	for _, s := range list {
+		var buf bytes.Buffer
+		if err := s.Write(&buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.Len() != s.Size() {
+			t.Errorf("expected Size to match the %d bytes written, got %d", buf.Len(), s.Size())
+		}
+		written = append(written, buf.Bytes()...)
+	}
+
+	if !bytes.Equal(written, raw) {
+		t.Errorf("expected the written file to match the original\nexpected: % x\ngot:      % x", raw, written)
+	}
+}
+
+func TestStandardSpeedDataWritePause(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/standard_speed_data.tzx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := readStandardBlocks(t, raw)[0]
+	s.SetPause(500)
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := append([]byte(nil), raw[10:10+s.Size()]...)
+	want[1], want[2] = 0xf4, 0x01
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("expected only the pause to change\nexpected: % x\ngot:      % x", want, buf.Bytes())
+	}
+}
@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -28,6 +29,9 @@ func (s *StopTapeWhen48kMode) Read(reader *storage.Reader) error {
 	}
 
 	s.Length = reader.ReadLong()
+	if s.Length != 0 {
+		return fmt.Errorf("invalid block length, expected 0 bytes, got %d", s.Length)
+	}
 
 	return nil
 }
@@ -39,14 +43,35 @@ func (s StopTapeWhen48kMode) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (s StopTapeWhen48kMode) Name() string {
-	return "Stop Tape when in 48k Mode"
+	return blockName(s.Id(), "Stop Tape when in 48k Mode")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (s StopTapeWhen48kMode) Category() types.BlockCategory {
+	return types.CategoryFlowControl
 }
 
 func (s StopTapeWhen48kMode) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (s StopTapeWhen48kMode) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (s StopTapeWhen48kMode) String() string {
 	return fmt.Sprintf("%s", s.Name())
 }
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (s StopTapeWhen48kMode) Size() int {
+	return 5
+}
+
+// Write the block to the writer, in the TZX file format.
+func (s StopTapeWhen48kMode) Write(w io.Writer) error {
+	return writeFields(w, s.Id(), uint32(0))
+}
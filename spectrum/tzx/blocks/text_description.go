@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -45,14 +46,38 @@ func (t TextDescription) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (t TextDescription) Name() string {
-	return "Text Description"
+	return blockName(t.Id(), "Text Description")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (t TextDescription) Category() types.BlockCategory {
+	return types.CategoryMetadata
 }
 
 func (t TextDescription) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns 0 as this block has no pause.
+func (t TextDescription) PauseMs() uint16 {
+	return 0
+}
+
 // String returns a human readable string of the block data
 func (t TextDescription) String() string {
-	return fmt.Sprintf("%-19s : %s", t.Name(), t.Description)
+	return fmt.Sprintf("%-19s : %s", t.Name(), decodeLatin1(t.Description))
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (t TextDescription) Size() int {
+	return 2 + len(t.Description)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (t TextDescription) Write(w io.Writer) error {
+	if err := checkLength("description", len(t.Description), 0xff); err != nil {
+		return err
+	}
+	return writeFields(w, t.Id(), uint8(len(t.Description)), t.Description)
 }
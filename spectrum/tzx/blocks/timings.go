@@ -0,0 +1,74 @@
+package blocks
+
+// Standard Spectrum ROM timing values, in T-states, as used when replaying
+// a StandardSpeedData block. These are the default values given in the
+// curly brackets of the TurboSpeedData block.
+const (
+	StandardPilotPulse      = 2168 // Length of PILOT pulse
+	StandardSyncFirstPulse  = 667  // Length of SYNC first pulse
+	StandardSyncSecondPulse = 735  // Length of SYNC second pulse
+	StandardZeroBitPulse    = 855  // Length of ZERO bit pulse
+	StandardOneBitPulse     = 1710 // Length of ONE bit pulse
+	StandardHeaderPilotTone = 8063 // Number of PILOT pulses for a header block (flag < 128)
+	StandardDataPilotTone   = 3223 // Number of PILOT pulses for a data block (flag >= 128)
+)
+
+// TStatesPerSecond is the Z80 clock speed; 1 T-state = (1/3500000)s.
+const TStatesPerSecond = 3500000
+
+// DataTStates returns the number of T-states needed to play the given data
+// bytes, where each bit is made up of two pulses of the zero or one length.
+// Only `usedBits` of the last byte are played, MSb first.
+func DataTStates(data []byte, usedBits uint8, zeroBitPulse, oneBitPulse uint16) uint64 {
+	var duration uint64
+
+	for i, b := range data {
+		bitCount := 8
+		if i == len(data)-1 && usedBits > 0 && usedBits < 8 {
+			bitCount = int(usedBits)
+		}
+
+		for bit := 0; bit < bitCount; bit++ {
+			if b&(0x80>>uint(bit)) != 0 {
+				duration += 2 * uint64(oneBitPulse)
+			} else {
+				duration += 2 * uint64(zeroBitPulse)
+			}
+		}
+	}
+
+	return duration
+}
+
+// DataPulses returns the pulse lengths for the given data bytes, with two
+// pulses of the zero or one length for each bit. Only `usedBits` of the last
+// byte are included, MSb first.
+func DataPulses(data []byte, usedBits uint8, zeroBitPulse, oneBitPulse uint16) []uint16 {
+	var pulses []uint16
+
+	for i, b := range data {
+		bitCount := 8
+		if i == len(data)-1 && usedBits > 0 && usedBits < 8 {
+			bitCount = int(usedBits)
+		}
+
+		for bit := 0; bit < bitCount; bit++ {
+			if b&(0x80>>uint(bit)) != 0 {
+				pulses = append(pulses, oneBitPulse, oneBitPulse)
+			} else {
+				pulses = append(pulses, zeroBitPulse, zeroBitPulse)
+			}
+		}
+	}
+
+	return pulses
+}
+
+// withinTolerance reports whether the value is within the given percentage of the standard value.
+func withinTolerance(value, standard uint16, percent int) bool {
+	diff := int(value) - int(standard)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 <= int(standard)*percent
+}
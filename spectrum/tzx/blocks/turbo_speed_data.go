@@ -2,6 +2,7 @@ package blocks
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/mrcook/retroio/spectrum/tap"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
@@ -24,7 +25,7 @@ type TurboSpeedData struct {
 	PilotTone       uint16   // Length of PILOT tone (number of pulses) {8063 header (flag<128), 3223 data (flag>=128)}
 	UsedBits        uint8    // Used bits in the last byte (other bits should be 0) {8} (e.g. if this is 6, then the bits used (x) in the last byte are: xxxxxx00, where MSb is the leftmost bit, LSb is the rightmost bit)
 	Pause           uint16   // Pause after this block (ms.) {1000}
-	Length          [3]uint8 // Length of data that follows.
+	Length          [3]uint8 // Length of data that follows (a 3-byte value, not a WORD and a spare byte).
 	DataBlock       []uint8  // Data as in .TAP files
 
 	displayLength uint32
@@ -52,9 +53,13 @@ func (t *TurboSpeedData) Read(reader *storage.Reader) error {
 	t.displayLength = reader.Bytes3ToLong(t.Length)
 
 	// TODO: read this as TAP data.
-	t.DataBlock = make([]byte, t.displayLength)
-	_, err := reader.Read(t.DataBlock)
-	return err
+	data, err := readLength(reader, uint64(t.displayLength))
+	if err != nil {
+		return err
+	}
+	t.DataBlock = data
+
+	return nil
 }
 
 // Id of the block as given in the TZX specification, written as a hexadecimal number.
@@ -64,14 +69,121 @@ func (t TurboSpeedData) Id() types.BlockType {
 
 // Name of the block as given in the TZX specification.
 func (t TurboSpeedData) Name() string {
-	return "Turbo Speed Data"
+	return blockName(t.Id(), "Turbo Speed Data")
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (t TurboSpeedData) Category() types.BlockCategory {
+	return types.CategoryData
 }
 
 func (t TurboSpeedData) BlockData() tap.Block {
 	return nil
 }
 
+// PauseMs returns the pause after this block in milliseconds.
+func (t TurboSpeedData) PauseMs() uint16 {
+	return t.Pause
+}
+
+// SetPause sets the pause after this block in milliseconds.
+func (t *TurboSpeedData) SetPause(ms uint16) {
+	t.Pause = ms
+}
+
 // String returns a human readable string of the block data
 func (t TurboSpeedData) String() string {
 	return fmt.Sprintf("%-19s : %d bytes, pause for %d ms.", t.Name(), t.displayLength, t.Pause)
 }
+
+// PilotDurationTStates returns the length of the pilot tone and the two sync
+// pulses, in T-states. This is the loader overhead that precedes the data.
+func (t TurboSpeedData) PilotDurationTStates() uint32 {
+	duration := uint32(t.PilotTone) * uint32(t.PilotPulse)
+	duration += uint32(t.SyncFirstPulse) + uint32(t.SyncSecondPulse)
+	return duration
+}
+
+// DataDurationTStates returns the length of the data bit stream, in T-states.
+// Each bit is encoded as two pulses, and only `UsedBits` of the last byte are played.
+func (t TurboSpeedData) DataDurationTStates() uint32 {
+	return uint32(DataTStates(t.DataBlock, t.UsedBits, t.ZeroBitPulse, t.OneBitPulse))
+}
+
+// SetData replaces the data bytes of the block, keeping the existing flag
+// byte, and recalculates the length and checksum values.
+func (t *TurboSpeedData) SetData(data []byte) error {
+	// flag and checksum bytes are included in the length
+	length := len(data) + 2
+	if length > 0xffffff {
+		return fmt.Errorf("data length of %d bytes is too large for a TurboSpeedData block", len(data))
+	}
+
+	var flag uint8
+	if len(t.DataBlock) > 0 {
+		flag = t.DataBlock[0]
+	}
+
+	t.DataBlock = append([]byte{flag}, data...)
+	t.DataBlock = append(t.DataBlock, checksum(flag, data))
+
+	t.Length = [3]uint8{uint8(length), uint8(length >> 8), uint8(length >> 16)}
+	t.displayLength = uint32(length)
+
+	return nil
+}
+
+// ChecksumValid reports whether the last data byte matches the XOR of the
+// flag and data bytes, as used by the ROM loading routines. Custom loaders
+// may not use a checksum. Blocks too short to contain a flag and checksum
+// are invalid.
+func (t TurboSpeedData) ChecksumValid() bool {
+	return checksumValid(t.DataBlock)
+}
+
+// LikelyCustomLoader reports whether the block is likely to be loaded by a
+// custom loader (e.g. a protection scheme), rather than the ROM routines.
+// This is the case when any of the pulse timings differ from the ROM values
+// by more than 5%, the pilot tone length is not one the ROM would produce,
+// or the last byte does not use all 8 bits.
+func (t TurboSpeedData) LikelyCustomLoader() bool {
+	if t.UsedBits != 8 {
+		return true
+	}
+
+	timings := []struct{ value, standard uint16 }{
+		{t.PilotPulse, StandardPilotPulse},
+		{t.SyncFirstPulse, StandardSyncFirstPulse},
+		{t.SyncSecondPulse, StandardSyncSecondPulse},
+		{t.ZeroBitPulse, StandardZeroBitPulse},
+		{t.OneBitPulse, StandardOneBitPulse},
+	}
+	for _, timing := range timings {
+		if !withinTolerance(timing.value, timing.standard, 5) {
+			return true
+		}
+	}
+
+	// the ROM routines only need a short pilot tone, so some variation is allowed
+	if !withinTolerance(t.PilotTone, StandardHeaderPilotTone, 10) && !withinTolerance(t.PilotTone, StandardDataPilotTone, 10) {
+		return true
+	}
+
+	return false
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (t TurboSpeedData) Size() int {
+	return 19 + len(t.DataBlock)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (t TurboSpeedData) Write(w io.Writer) error {
+	length, err := longTo3Bytes(len(t.DataBlock))
+	if err != nil {
+		return err
+	}
+	return writeFields(w, t.Id(), t.PilotPulse, t.SyncFirstPulse, t.SyncSecondPulse, t.ZeroBitPulse,
+		t.OneBitPulse, t.PilotTone, t.UsedBits, t.Pause, length, t.DataBlock)
+}
@@ -0,0 +1,108 @@
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+// romTimingTurbo returns a TurboSpeedData block using the ROM loader timings.
+func romTimingTurbo(data []byte) *TurboSpeedData {
+	return &TurboSpeedData{
+		PilotPulse:      2168,
+		SyncFirstPulse:  667,
+		SyncSecondPulse: 735,
+		ZeroBitPulse:    855,
+		OneBitPulse:     1710,
+		PilotTone:       3223,
+		UsedBits:        8,
+		Pause:           1000,
+		DataBlock:       data,
+	}
+}
+
+func TestTurboSpeedDataDurations(t *testing.T) {
+	b := romTimingTurbo([]byte{0xff, 0x00})
+
+	// 3223 pilot pulses, plus the two sync pulses
+	if got := b.PilotDurationTStates(); got != 3223*2168+667+735 {
+		t.Errorf("expected a pilot duration of %d T-states, got %d", 3223*2168+667+735, got)
+	}
+	// eight one bits and eight zero bits, of two pulses each
+	if got := b.DataDurationTStates(); got != 8*2*1710+8*2*855 {
+		t.Errorf("expected a data duration of %d T-states, got %d", 8*2*1710+8*2*855, got)
+	}
+
+	b.UsedBits = 4
+	if got := b.DataDurationTStates(); got != 8*2*1710+4*2*855 {
+		t.Errorf("expected a data duration of %d T-states with 4 used bits, got %d", 8*2*1710+4*2*855, got)
+	}
+}
+
+func TestTurboSpeedDataLikelyCustomLoader(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *TurboSpeedData)
+		custom bool
+	}{
+		{"ROM timings", func(t *TurboSpeedData) {}, false},
+		{"ROM timings within tolerance", func(t *TurboSpeedData) { t.ZeroBitPulse = 870 }, false},
+		{"data pilot tone", func(t *TurboSpeedData) { t.PilotTone = 8063 }, false},
+		{"fast bit timings", func(t *TurboSpeedData) { t.ZeroBitPulse, t.OneBitPulse = 520, 1040 }, true},
+		{"short pilot tone", func(t *TurboSpeedData) { t.PilotTone = 500 }, true},
+		{"partial last byte", func(t *TurboSpeedData) { t.UsedBits = 6 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := romTimingTurbo([]byte{0xff, 0x01, 0xfe})
+			tt.modify(block)
+			if got := block.LikelyCustomLoader(); got != tt.custom {
+				t.Errorf("expected custom loader to be %t, got %t", tt.custom, got)
+			}
+		})
+	}
+}
+
+func TestTurboSpeedDataThreeByteLength(t *testing.T) {
+	var buf bytes.Buffer
+	if err := romTimingTurbo(make([]byte, 0x10002)).Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw := append(buf.Bytes(), 0x20) // the next block ID
+	if raw[16] != 0x02 || raw[17] != 0x00 || raw[18] != 0x01 {
+		t.Fatalf("expected a 3-byte length of 02 00 01, got % x", raw[16:19])
+	}
+
+	reader := storage.NewReader(bytes.NewReader(raw))
+	reader.FileSize = len(raw)
+
+	var turbo TurboSpeedData
+	if err := turbo.Read(reader); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(turbo.DataBlock) != 0x10002 {
+		t.Errorf("expected 65538 data bytes, got %d", len(turbo.DataBlock))
+	}
+	if next := reader.ReadByte(); next != 0x20 {
+		t.Errorf("expected the next block ID to follow the data, got 0x%02x", next)
+	}
+}
+
+func TestTurboSpeedDataLengthTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	if err := romTimingTurbo([]byte{1, 2, 3}).Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw := buf.Bytes()
+	raw[18] = 0x01 // a length of 65539 bytes
+
+	reader := storage.NewReader(bytes.NewReader(raw))
+	reader.FileSize = len(raw)
+
+	var turbo TurboSpeedData
+	if err := turbo.Read(reader); err == nil {
+		t.Error("expected an error for a length running past the end of the file")
+	}
+}
@@ -0,0 +1,32 @@
+package types
+
+// BlockCategory is the purpose of a block, which can be used to group,
+// colour code, or filter the blocks of a tape.
+type BlockCategory uint8
+
+const (
+	CategoryUnknown     BlockCategory = iota // blocks not in the TZX specification
+	CategoryData                             // blocks carrying program or snapshot data
+	CategoryTiming                           // tones, pulses, pauses and signal levels
+	CategoryAudio                            // sampled recordings of the signal
+	CategoryFlowControl                      // jumps, loops, calls, and selections
+	CategoryMetadata                         // groups, text, messages, and custom info
+	CategoryArchive                          // the archive info block
+	CategoryHardware                         // hardware and emulation info
+)
+
+var categoryNames = map[BlockCategory]string{
+	CategoryData:        "data",
+	CategoryTiming:      "timing",
+	CategoryAudio:       "audio",
+	CategoryFlowControl: "flow",
+	CategoryMetadata:    "metadata",
+	CategoryArchive:     "archive",
+	CategoryHardware:    "hardware",
+}
+
+// String returns the lowercase name of the category, or an empty string
+// for unknown blocks.
+func (c BlockCategory) String() string {
+	return categoryNames[c]
+}
@@ -0,0 +1,75 @@
+package blocks
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+	"github.com/mrcook/retroio/storage"
+)
+
+// UnknownBlock
+// Stores the data of a block with an unsupported ID. The TZX specification
+// states that all blocks added after v1.10 have the length of the block in
+// the first 4 bytes after the ID, which is used to read the block data.
+type UnknownBlock struct {
+	BlockID types.BlockType
+	Length  uint32  // Block length (without these four bytes)
+	Data    []uint8 // Block data
+}
+
+// Read the tape and extract the data.
+// It is expected that the tape pointer is at the correct position for reading.
+func (u *UnknownBlock) Read(reader *storage.Reader) error {
+	u.BlockID = types.BlockType(reader.ReadByte())
+	u.Length = reader.ReadLong()
+
+	data, err := readLength(reader, uint64(u.Length))
+	if err != nil {
+		return fmt.Errorf("unknown block 0x%02X does not match the block length of %d bytes: %v", uint8(u.BlockID), u.Length, err)
+	}
+	u.Data = data
+
+	return nil
+}
+
+// Id of the block, as read from the tape.
+func (u UnknownBlock) Id() types.BlockType {
+	return u.BlockID
+}
+
+// Name of the block.
+func (u UnknownBlock) Name() string {
+	return "Unknown Block"
+}
+
+// Category of the block, for grouping the blocks of a tape.
+func (u UnknownBlock) Category() types.BlockCategory {
+	return types.CategoryUnknown
+}
+
+func (u UnknownBlock) BlockData() tap.Block {
+	return nil
+}
+
+// PauseMs returns 0 as this block has no pause.
+func (u UnknownBlock) PauseMs() uint16 {
+	return 0
+}
+
+// String returns a human readable string of the block data
+func (u UnknownBlock) String() string {
+	return fmt.Sprintf("%-19s : ID 0x%02X, %d bytes", u.Name(), uint8(u.BlockID), u.Length)
+}
+
+// Size returns the length of the block in bytes, as written in the TZX
+// file format, including the ID byte.
+func (u UnknownBlock) Size() int {
+	return 5 + len(u.Data)
+}
+
+// Write the block to the writer, in the TZX file format.
+func (u UnknownBlock) Write(w io.Writer) error {
+	return writeFields(w, u.BlockID, uint32(len(u.Data)), u.Data)
+}
@@ -0,0 +1,36 @@
+package blocks
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// writeFields writes each of the values, in little endian byte order.
+// Values must be fixed size, or slices of fixed size values.
+func writeFields(w io.Writer, values ...interface{}) error {
+	for _, v := range values {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// longTo3Bytes converts a length to the 3 little endian ordered bytes used
+// by a number of blocks, returning an error if the value is too large.
+func longTo3Bytes(length int) ([3]uint8, error) {
+	if length > 0xffffff {
+		return [3]uint8{}, fmt.Errorf("length of %d bytes is too large for a 3-byte value", length)
+	}
+	return [3]uint8{uint8(length), uint8(length >> 8), uint8(length >> 16)}, nil
+}
+
+// checkLength returns an error if the length of some data is larger than
+// its length field can hold.
+func checkLength(name string, length, max int) error {
+	if length > max {
+		return fmt.Errorf("%s length of %d is too large, the maximum is %d", name, length, max)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package tzx
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// BlockCategory returns the purpose of the block, which can be used to group
+// or colour code blocks: "data", "timing", "flow", "metadata", "hardware", or
+// "audio". The archive info block is reported as "metadata", and an empty
+// string is returned for unknown blocks. Use the block's Category method for
+// the full set of categories.
+func BlockCategory(b Block) string {
+	if b.Category() == types.CategoryArchive {
+		return types.CategoryMetadata.String()
+	}
+	return b.Category().String()
+}
@@ -0,0 +1,49 @@
+package tzx
+
+import (
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+func TestBlockCategory(t *testing.T) {
+	tests := []struct {
+		block Block
+		want  string
+	}{
+		{&blocks.StandardSpeedData{}, "data"},
+		{&blocks.TurboSpeedData{}, "data"},
+		{&blocks.PureData{}, "data"},
+		{&blocks.GeneralizedData{}, "data"},
+		{&blocks.Snapshot{}, "data"},
+		{&blocks.PureTone{}, "timing"},
+		{&blocks.SequenceOfPulses{}, "timing"},
+		{&blocks.PauseTapeCommand{}, "timing"},
+		{&blocks.SetSignalLevel{}, "timing"},
+		{&blocks.DirectRecording{}, "audio"},
+		{&blocks.CswRecording{}, "audio"},
+		{&blocks.JumpTo{}, "flow"},
+		{&blocks.LoopStart{}, "flow"},
+		{&blocks.LoopEnd{}, "flow"},
+		{&blocks.CallSequence{}, "flow"},
+		{&blocks.ReturnFromSequence{}, "flow"},
+		{&blocks.Select{}, "flow"},
+		{&blocks.StopTapeWhen48kMode{}, "flow"},
+		{&blocks.GroupStart{}, "metadata"},
+		{&blocks.GroupEnd{}, "metadata"},
+		{&blocks.TextDescription{}, "metadata"},
+		{&blocks.Message{}, "metadata"},
+		{&blocks.ArchiveInfo{}, "metadata"},
+		{&blocks.CustomInfo{}, "metadata"},
+		{&blocks.GlueBlock{}, "metadata"},
+		{&blocks.HardwareType{}, "hardware"},
+		{&blocks.EmulationInfo{}, "hardware"},
+		{&blocks.UnknownBlock{BlockID: 0x60}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := BlockCategory(tt.block); got != tt.want {
+			t.Errorf("%s: expected category %q, got %q", tt.block.Name(), tt.want, got)
+		}
+	}
+}
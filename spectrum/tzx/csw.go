@@ -0,0 +1,274 @@
+package tzx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// CSW (Compressed Square Wave) v2 file header.
+// The header is followed by the pulse data, compressed as stated by the
+// compression type: 1 = RLE, 2 = Z-RLE (zlib deflated RLE).
+type cswHeader struct {
+	Signature       [22]byte // `Compressed Square Wave`
+	Terminator      uint8    // 0x1A
+	MajorVersion    uint8    // CSW major revision number (2)
+	MinorVersion    uint8    // CSW minor revision number (0)
+	SampleRate      uint32   // Sample rate
+	PulseCount      uint32   // Total number of pulses (after decompression)
+	CompressionType uint8    // Compression type: 0x01 = RLE, 0x02 = Z-RLE
+	Flags           uint8    // b0: initial polarity; if set, the signal starts at logical high
+	ExtensionLength uint8    // Header extension length in bytes
+	Application     [16]byte // Encoding application description
+}
+
+// cswV2Fields are the CSW v2 header fields following the version numbers.
+type cswV2Fields struct {
+	SampleRate      uint32
+	PulseCount      uint32
+	CompressionType uint8
+	Flags           uint8
+	ExtensionLength uint8
+	Application     [16]byte
+}
+
+// CSW v1 file header fields following the version numbers, where only RLE
+// compression is supported.
+type cswV1Header struct {
+	SampleRate      uint16  // Sample rate
+	CompressionType uint8   // Compression type: 0x01 = RLE
+	Flags           uint8   // b0: initial polarity; if set, the signal starts at logical high
+	Reserved        [3]byte // Reserved
+}
+
+// newCSWHeader returns a CSW v2 header for the given values.
+func newCSWHeader(sampleRate, pulseCount uint32, compressionType uint8, initialHigh bool) cswHeader {
+	header := cswHeader{
+		Terminator:      0x1a,
+		MajorVersion:    2,
+		MinorVersion:    0,
+		SampleRate:      sampleRate,
+		PulseCount:      pulseCount,
+		CompressionType: compressionType,
+	}
+	if initialHigh {
+		header.Flags = 0x01
+	}
+	copy(header.Signature[:], "Compressed Square Wave")
+	copy(header.Application[:], "retroio")
+
+	return header
+}
+
+// WriteCSWFromPulses writes the pulses as a Z-RLE compressed CSW v2 file.
+// Each pulse length is given as a number of samples at the `sampleRate`.
+func WriteCSWFromPulses(w io.Writer, pulses []uint32, sampleRate uint32) error {
+	header := newCSWHeader(sampleRate, uint32(len(pulses)), 0x02, false)
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return errors.Wrap(err, "unable to write CSW header")
+	}
+
+	z := zlib.NewWriter(w)
+	if _, err := z.Write(encodeCSWPulses(pulses)); err != nil {
+		return errors.Wrap(err, "unable to write CSW data")
+	}
+	return z.Close()
+}
+
+// ExportCSW renders the tape as a CSW v2 file at the given sample rate,
+// using Z-RLE compression when `compress` is true, otherwise RLE. The pulses
+// are generated by StreamPulses, with consecutive pulses at the same level
+// joined, as a CSW file only stores the edges.
+//
+// The pulses are streamed to the writer, rather than buffered; the tape is
+// played twice, first to calculate the pulse count needed by the CSW header.
+func (t TZX) ExportCSW(w io.Writer, sampleRate int, compress bool) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	var count uint32
+	initialHigh, err := t.streamCSWPulses(sampleRate, func(samples uint32) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var compressionType uint8 = 0x01
+	if compress {
+		compressionType = 0x02
+	}
+	header := newCSWHeader(uint32(sampleRate), count, compressionType, initialHigh)
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return errors.Wrap(err, "unable to write CSW header")
+	}
+
+	var data io.Writer
+	var z *zlib.Writer
+	bw := bufio.NewWriter(w)
+	if compress {
+		z = zlib.NewWriter(bw)
+		data = z
+	} else {
+		data = bw
+	}
+
+	_, err = t.streamCSWPulses(sampleRate, func(samples uint32) error {
+		return writeCSWPulse(data, samples)
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to write CSW data")
+	}
+
+	if z != nil {
+		if err := z.Close(); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// streamCSWPulses plays the tape, calling fn with the length of each pulse
+// as a number of samples, joining consecutive pulses at the same level.
+// It returns true when the first pulse is at a high level.
+func (t TZX) streamCSWPulses(sampleRate int, fn func(samples uint32) error) (bool, error) {
+	var initialHigh, started, level bool
+	var pending uint64
+
+	err := t.StreamPulses(newSampler(sampleRate, func(l bool, count uint64) error {
+		if !started {
+			started, initialHigh, level = true, l, l
+		} else if l != level {
+			if err := fn(uint32(pending)); err != nil {
+				return err
+			}
+			pending, level = 0, l
+		}
+		pending += count
+		return nil
+	}))
+	if err != nil {
+		return false, err
+	}
+
+	if started {
+		if err := fn(uint32(pending)); err != nil {
+			return false, err
+		}
+	}
+
+	return initialHigh, nil
+}
+
+// ImportCSW reads a CSW v1 or v2 file, returning a tape containing the
+// recording as a single CswRecording block. When the CSW signal starts at
+// a high level, it is preceded by a SetSignalLevel block.
+func ImportCSW(r io.Reader) (*TZX, error) {
+	var signature [23]byte
+	var version [2]uint8
+	if err := binary.Read(r, binary.LittleEndian, &signature); err != nil {
+		return nil, errors.Wrap(err, "unable to read CSW header")
+	}
+	if string(signature[:22]) != "Compressed Square Wave" || signature[22] != 0x1a {
+		return nil, fmt.Errorf("not a CSW file, incorrect signature")
+	}
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, errors.Wrap(err, "unable to read CSW header")
+	}
+
+	csw := &blocks.CswRecording{BlockID: types.CswRecording}
+	var flags uint8
+
+	switch version[0] {
+	case 1:
+		var header cswV1Header
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return nil, errors.Wrap(err, "unable to read CSW header")
+		}
+		csw.SampleRate = uint32(header.SampleRate)
+		csw.CompressionType = header.CompressionType
+		flags = header.Flags
+	case 2:
+		var header cswV2Fields
+		if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+			return nil, errors.Wrap(err, "unable to read CSW header")
+		}
+		if _, err := io.CopyN(ioutil.Discard, r, int64(header.ExtensionLength)); err != nil {
+			return nil, errors.Wrap(err, "unable to read CSW header extension")
+		}
+		csw.SampleRate = header.SampleRate
+		csw.CompressionType = header.CompressionType
+		csw.StoredPulseCount = header.PulseCount
+		flags = header.Flags
+	default:
+		return nil, fmt.Errorf("unsupported CSW version: %d.%d", version[0], version[1])
+	}
+
+	if csw.SampleRate > 0xffffff {
+		return nil, fmt.Errorf("CSW sample rate of %d Hz is too large for a TZX block", csw.SampleRate)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read CSW data")
+	}
+	csw.Data = data
+	csw.Length = uint32(len(data)) + 10
+
+	// v1 files do not store the pulse count
+	if version[0] == 1 {
+		pulses, err := csw.Pulses()
+		if err != nil {
+			return nil, err
+		}
+		csw.StoredPulseCount = uint32(len(pulses))
+	}
+
+	var tapeBlocks []Block
+	if flags&0x01 != 0 {
+		tapeBlocks = append(tapeBlocks, &blocks.SetSignalLevel{BlockID: types.SetSignalLevel, Length: 1, SignalLevel: 1})
+	}
+	tapeBlocks = append(tapeBlocks, csw)
+
+	tape := &TZX{header: newHeader(tapeBlocks)}
+	tape.setTapeBlocks(tapeBlocks)
+
+	return tape, nil
+}
+
+// encodeCSWPulses RLE encodes the pulses.
+func encodeCSWPulses(pulses []uint32) []byte {
+	var buf bytes.Buffer
+
+	for _, p := range pulses {
+		_ = writeCSWPulse(&buf, p)
+	}
+
+	return buf.Bytes()
+}
+
+// writeCSWPulse RLE encodes a single pulse. Each pulse is stored as a single
+// byte, unless it is longer than 255 samples, in which case a zero byte is
+// written, followed by the length as a DWORD.
+func writeCSWPulse(w io.Writer, samples uint32) error {
+	if samples > 0 && samples <= 0xff {
+		_, err := w.Write([]byte{byte(samples)})
+		return err
+	}
+
+	b := []byte{0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(b[1:], samples)
+	_, err := w.Write(b)
+	return err
+}
@@ -0,0 +1,140 @@
+package tzx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// cswRoundTrip exports the tape as a CSW file, then imports it again.
+func cswRoundTrip(t *testing.T, tape *TZX, sampleRate int, compress bool) (*TZX, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := tape.ExportCSW(&buf, sampleRate, compress); err != nil {
+		t.Fatalf("unexpected export error: %v", err)
+	}
+	imported, err := ImportCSW(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+	return imported, buf.Bytes()
+}
+
+// allPulses returns the pulses of the tape, failing the test on an error.
+func allPulses(t *testing.T, tape *TZX) []Pulse {
+	t.Helper()
+
+	pulses, err := tape.AllPulses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pulses
+}
+
+// joinLevels joins consecutive pulses at the same level, as a CSW file only
+// stores the edges.
+func joinLevels(pulses []Pulse) []Pulse {
+	var joined []Pulse
+	for _, p := range pulses {
+		if n := len(joined); n > 0 && joined[n-1].Level == p.Level {
+			joined[n-1].TStates += p.TStates
+			continue
+		}
+		joined = append(joined, p)
+	}
+	return joined
+}
+
+func TestExportImportCSW(t *testing.T) {
+	// the pause of the standard block, and the Pause block, are joined
+	tape := readTape(t, tzxFile(20, pureTone(2168, 11), pureTone(1000, 4), standardBlock, []byte{0x20, 0x64, 0x00}))
+	want := joinLevels(allPulses(t, tape))
+
+	// at one sample per T-state the pulses are kept exactly
+	for _, compress := range []bool{false, true} {
+		imported, raw := cswRoundTrip(t, tape, blocks.TStatesPerSecond, compress)
+
+		wantType := uint8(0x01)
+		if compress {
+			wantType = 0x02
+		}
+		if raw[23] != 2 || raw[33] != wantType {
+			t.Errorf("expected a CSW v2 file with compression type %d, got v%d and type %d", wantType, raw[23], raw[33])
+		}
+		if count := binary.LittleEndian.Uint32(raw[29:33]); int(count) != len(want) {
+			t.Errorf("expected a header pulse count of %d, got %d", len(want), count)
+		}
+
+		if got := allPulses(t, imported); !reflect.DeepEqual(got, want) {
+			t.Errorf("compress %v: expected the imported pulses to match the tape\nexpected: %v\ngot:      %v", compress, want, got)
+		}
+	}
+}
+
+func TestImportCSWVersions(t *testing.T) {
+	tape := readTape(t, tzxFile(20, pureTone(2168, 11), standardBlock, []byte{0x20, 0x64, 0x00}))
+
+	rle, raw := cswRoundTrip(t, tape, 44100, false)
+	zrle, _ := cswRoundTrip(t, tape, 44100, true)
+	want := allPulses(t, rle)
+	if got := allPulses(t, zrle); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the Z-RLE pulses to match the RLE pulses\nexpected: %v\ngot:      %v", want, got)
+	}
+
+	// a v1 file holding the same RLE data, which is found after the 52 byte v2 header
+	v1 := append([]byte("Compressed Square Wave\x1a"), 1, 1, 0x44, 0xac, 0x01, 0x00, 0, 0, 0)
+	v1 = append(v1, raw[52:]...)
+	imported, err := ImportCSW(bytes.NewReader(v1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := allPulses(t, imported); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the v1 pulses to match the v2 pulses\nexpected: %v\ngot:      %v", want, got)
+	}
+}
+
+func TestWriteCSWFromPulses(t *testing.T) {
+	pulses := []uint32{10, 20, 300, 1}
+
+	var buf bytes.Buffer
+	if err := WriteCSWFromPulses(&buf, pulses, 44100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tape, err := ImportCSW(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	csw := tape.Blocks()[0].(*blocks.CswRecording)
+	if csw.CompressionType != 0x02 || csw.StoredPulseCount != 4 {
+		t.Errorf("expected Z-RLE with 4 stored pulses, got type %d with %d", csw.CompressionType, csw.StoredPulseCount)
+	}
+	got, err := csw.Pulses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, pulses) {
+		t.Errorf("expected pulses %v, got %v", pulses, got)
+	}
+}
+
+func TestExportImportCSWInitialHigh(t *testing.T) {
+	setHigh := []byte{0x2b, 0x01, 0x00, 0x00, 0x00, 0x01}
+	tape := readTape(t, tzxFile(20, setHigh, pureTone(2168, 3)))
+	want := allPulses(t, tape)
+
+	imported, raw := cswRoundTrip(t, tape, blocks.TStatesPerSecond, true)
+	if raw[34]&0x01 == 0 {
+		t.Error("expected the initial polarity flag to be set")
+	}
+	if _, ok := imported.Blocks()[0].(*blocks.SetSignalLevel); !ok {
+		t.Errorf("expected a SetSignalLevel block first, got %T", imported.Blocks()[0])
+	}
+	if got := allPulses(t, imported); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the imported pulses to match the tape\nexpected: %v\ngot:      %v", want, got)
+	}
+}
@@ -0,0 +1,67 @@
+package tzx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// WriteDOT writes the structure of the tape as a Graphviz DOT graph. Each
+// block is a node, with edges for the sequential flow, jumps, calls, selections
+// and loop-backs, which can then be rendered using the `dot` command.
+func (t TZX) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph tzx {\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for i, block := range t.blocks {
+		sb.WriteString(fmt.Sprintf("  b%d [label=%q];\n", i, fmt.Sprintf("#%02d %s", i+1, block.Name())))
+	}
+
+	edge := func(from, to int, label string) {
+		if to < 0 || to >= len(t.blocks) {
+			return
+		}
+		if label == "" {
+			sb.WriteString(fmt.Sprintf("  b%d -> b%d;\n", from, to))
+		} else {
+			sb.WriteString(fmt.Sprintf("  b%d -> b%d [label=%q, style=dashed];\n", from, to, label))
+		}
+	}
+
+	var loopStarts []int
+	for i, block := range t.blocks {
+		switch b := block.(type) {
+		case *blocks.JumpTo:
+			edge(i, i+int(b.Value), "jump")
+			continue // the next block is never played directly after a jump
+		case *blocks.CallSequence:
+			for _, offset := range b.Blocks {
+				edge(i, i+int(int16(offset)), "call")
+			}
+		case *blocks.Select:
+			for _, s := range b.Selections {
+				edge(i, i+int(s.RelativeOffset), "select")
+			}
+		case *blocks.LoopStart:
+			loopStarts = append(loopStarts, i)
+		case *blocks.LoopEnd:
+			if len(loopStarts) > 0 {
+				edge(i, loopStarts[len(loopStarts)-1]+1, "loop")
+				loopStarts = loopStarts[:len(loopStarts)-1]
+			}
+		}
+
+		if block.Id() != types.ReturnFromSequence {
+			edge(i, i+1, "")
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,34 @@
+package tzx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestWriteDOT(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x30, 0x02, 'h', 'i'},         // 0: text description
+		[]byte{0x24, 0x02, 0x00},             // 1: loop start
+		pureTone(2168, 10),                   // 2
+		[]byte{0x25},                         // 3: loop end
+		[]byte{0x26, 0x01, 0x00, 0x02, 0x00}, // 4: call 6
+		[]byte{0x23, 0x03, 0x00},             // 5: jump to 8
+		standardBlock,                        // 6
+		[]byte{0x27},                         // 7: return
+		[]byte{0x20, 0x00, 0x00},             // 8: stop the tape
+	)
+
+	var buf bytes.Buffer
+	if err := readTape(t, raw).WriteDOT(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want, err := ioutil.ReadFile("testdata/flow.dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("expected DOT output:\n%s\ngot:\n%s", want, buf.Bytes())
+	}
+}
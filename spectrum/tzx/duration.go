@@ -0,0 +1,94 @@
+package tzx
+
+import (
+	"time"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Duration returns the estimated playback time of the tape, calculated from
+// the pulse timings of each data block, plus any pauses. The blocks are timed
+// in the order given by LinearBlocks, so loops are counted once for each
+// repetition. If the flow control blocks are invalid then each block is
+// timed once, in file order.
+func (t TZX) Duration() time.Duration {
+	var tStates uint64
+	var pause time.Duration
+
+	played, err := t.LinearBlocks()
+	if err != nil {
+		played = t.blocks
+	}
+
+	for _, block := range played {
+		ts, ms := blockTiming(block)
+		tStates += ts
+		pause += msToDuration(ms)
+	}
+
+	return tStatesToDuration(tStates) + pause
+}
+
+// EstimatedCassetteMinutes returns the playback time of the tape in minutes,
+// which can be used to decide which cassette length (C15, C60...) it fits on.
+func (t TZX) EstimatedCassetteMinutes() float64 {
+	return t.Duration().Minutes()
+}
+
+// blockTiming returns the number of T-states used to play the pulses of a
+// block, along with the length of any pause (in ms.) following them.
+func blockTiming(block Block) (tStates uint64, pause uint16) {
+	return blockTStates(block), block.PauseMs()
+}
+
+// blockTStates returns the number of T-states used to play the pulses of a block.
+func blockTStates(block Block) uint64 {
+	var tStates uint64
+
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		tStates = uint64(b.PilotDurationTStates()) + uint64(b.DataDurationTStates())
+	case *blocks.TurboSpeedData:
+		tStates = uint64(b.PilotDurationTStates()) + uint64(b.DataDurationTStates())
+	case *blocks.PureTone:
+		tStates = uint64(b.TStates())
+	case *blocks.SequenceOfPulses:
+		tStates = uint64(b.TStates())
+	case *blocks.PureData:
+		tStates = uint64(b.DataDurationTStates())
+	case *blocks.DirectRecording:
+		tStates = uint64(b.SampleCount()) * uint64(b.TStatesPerSample)
+	case *blocks.CswRecording:
+		tStates = cswTStates(b)
+	case *blocks.GeneralizedData:
+		_, tStates, _ = generalizedDataPulses(b, false)
+	}
+
+	return tStates
+}
+
+// cswTStates returns the length of the CSW pulses in T-states, converted from
+// the total number of samples. A recording that can not be decompressed, or
+// has a sample rate of 0, has no length.
+func cswTStates(b *blocks.CswRecording) uint64 {
+	pulses, err := b.Pulses()
+	if err != nil || b.SampleRate == 0 {
+		return 0
+	}
+
+	var samples uint64
+	for _, p := range pulses {
+		samples += uint64(p)
+	}
+	return samples * blocks.TStatesPerSecond / uint64(b.SampleRate)
+}
+
+func msToDuration(ms uint16) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func tStatesToDuration(tStates uint64) time.Duration {
+	seconds := tStates / blocks.TStatesPerSecond
+	remainder := tStates % blocks.TStatesPerSecond
+	return time.Duration(seconds)*time.Second + time.Duration(remainder)*time.Second/blocks.TStatesPerSecond
+}
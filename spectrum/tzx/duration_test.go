@@ -0,0 +1,67 @@
+package tzx
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+func TestBlockTStates(t *testing.T) {
+	tests := []struct {
+		name  string
+		block Block
+		want  uint64
+	}{
+		{
+			// 0 is followed by a long pulse of 44100 samples, which is 1 second
+			"csw recording",
+			&blocks.CswRecording{BlockID: types.CswRecording, SampleRate: 44100, CompressionType: 1, StoredPulseCount: 1, Data: []byte{0, 0x44, 0xac, 0, 0}},
+			blocks.TStatesPerSecond,
+		},
+		{
+			"generalized data",
+			&blocks.GeneralizedData{
+				BlockID:      types.GeneralizedData,
+				TOTP:         1,
+				NPP:          2,
+				ASP:          1,
+				PilotSymbols: []blocks.Symbol{{PulseLengths: []uint16{2168, 0}}},
+				PilotStreams: []blocks.PilotRLE{{Symbol: 0, RepetitionCount: 100}},
+				TOTD:         8,
+				NPD:          2,
+				ASD:          2,
+				DataSymbols:  []blocks.Symbol{{PulseLengths: []uint16{855, 855}}, {PulseLengths: []uint16{1710, 1710}}},
+				DataStreams:  []byte{0xf0},
+			},
+			100*2168 + 4*2*855 + 4*2*1710,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blockTStates(tt.block); got != tt.want {
+				t.Errorf("expected %d T-states, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEstimatedCassetteMinutes(t *testing.T) {
+	raw := tzxFile(20,
+		pureTone(2000, 1750),     // 1 second
+		[]byte{0x24, 0x02, 0x00}, // loop start, 2 repetitions
+		[]byte{0x20, 0x30, 0x75}, // 30 second pause
+		[]byte{0x25},             // loop end
+	)
+	tape := readTape(t, raw)
+
+	if got := tape.Duration(); got != 61*time.Second {
+		t.Errorf("expected a duration of 61s, got %v", got)
+	}
+	if got := tape.EstimatedCassetteMinutes(); math.Abs(got-61.0/60) > 1e-9 {
+		t.Errorf("expected %.4f minutes, got %.4f", 61.0/60, got)
+	}
+}
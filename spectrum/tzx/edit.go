@@ -0,0 +1,302 @@
+package tzx
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+	"github.com/mrcook/retroio/storage"
+)
+
+// Slice returns a new tape containing the blocks from index start up to,
+// but not including, end. Block indexes start from 0 and include any
+// ArchiveInfo block. As flow control blocks use relative offsets they remain
+// valid, however an error is returned if any of them refer to a block outside
+// of the slice, or a loop or call sequence is cut by the start or end of the
+// slice. The blocks are copied, so editing the new tape does not change the
+// original.
+func (t *TZX) Slice(start, end int) (*TZX, error) {
+	if start < 0 || end > len(t.blocks) || start > end {
+		return nil, fmt.Errorf("invalid slice range [%d:%d] for tape with %d blocks", start, end, len(t.blocks))
+	}
+
+	for i := start; i < end; i++ {
+		for _, target := range flowTargets(i, t.blocks[i]) {
+			if target < start || target >= end {
+				return nil, fmt.Errorf("block #%02d (%s) refers to block #%02d, which is outside the slice", i+1, t.blocks[i].Name(), target+1)
+			}
+		}
+	}
+	if err := t.checkSliceFlow(start, end); err != nil {
+		return nil, err
+	}
+
+	tape := &TZX{header: t.header, options: t.options, writerOptions: t.writerOptions}
+	for i := start; i < end; i++ {
+		block, err := t.copyBlock(t.blocks[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to copy block #%02d", i+1)
+		}
+		tape.blocks = append(tape.blocks, block)
+	}
+
+	return tape, nil
+}
+
+// checkSliceFlow returns an error if a LoopStart/LoopEnd pair, or a
+// CallSequence and the ReturnFromSequence of one of its calls, has one block
+// inside the slice and the other outside.
+func (t TZX) checkSliceFlow(start, end int) error {
+	var loops []int
+	for i := start; i < end; i++ {
+		switch t.blocks[i].Id() {
+		case types.LoopStart:
+			loops = append(loops, i)
+		case types.LoopEnd:
+			if len(loops) == 0 {
+				return fmt.Errorf("loop end at block #%02d has its loop start outside the slice", i+1)
+			}
+			loops = loops[:len(loops)-1]
+		}
+	}
+	if len(loops) > 0 {
+		return fmt.Errorf("loop at block #%02d has its loop end outside the slice", loops[0]+1)
+	}
+
+	for i, block := range t.blocks {
+		if block.Id() != types.CallSequence {
+			continue
+		}
+		inside := i >= start && i < end
+		for _, target := range flowTargets(i, block) {
+			ret := returnIndex(t.blocks, target)
+			if ret < 0 {
+				continue
+			}
+			if inside != (ret >= start && ret < end) {
+				return fmt.Errorf("call sequence at block #%02d returns at block #%02d, which is cut by the slice", i+1, ret+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+// returnIndex returns the index of the first ReturnFromSequence block at, or
+// after, the called block, or -1 if there is none.
+func returnIndex(tapeBlocks []Block, called int) int {
+	if called < 0 {
+		return -1
+	}
+	for i := called; i < len(tapeBlocks); i++ {
+		if tapeBlocks[i].Id() == types.ReturnFromSequence {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyBlock returns a deep copy of the block, made by writing it in the TZX
+// format and reading it back, so no data is shared with the original block.
+// The BlockFactory option is used for the copy when available.
+func (t TZX) copyBlock(block Block) (Block, error) {
+	var buf bytes.Buffer
+	if err := writeBlock(&buf, block); err != nil {
+		return nil, err
+	}
+
+	var copied Block
+	if t.options.BlockFactory != nil {
+		copied = t.options.BlockFactory(uint8(block.Id()))
+	}
+	if copied == nil {
+		if _, ok := block.(*blocks.UnknownBlock); ok {
+			copied = &blocks.UnknownBlock{}
+		} else {
+			var err error
+			if copied, err = newFromBlockID(uint8(block.Id())); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := copied.Read(storage.NewReader(bytes.NewReader(buf.Bytes()))); err != nil {
+		return nil, err
+	}
+	if archive, ok := block.(*blocks.ArchiveInfo); ok && archive.RawBytes != nil {
+		if c, ok := copied.(*blocks.ArchiveInfo); ok {
+			c.RawBytes = append([]byte(nil), archive.RawBytes...)
+		}
+	}
+
+	return copied, nil
+}
+
+// flowTargets returns the absolute block indexes referenced by the relative
+// offsets of the JumpTo, CallSequence and Select flow control blocks.
+func flowTargets(index int, block Block) []int {
+	var targets []int
+
+	switch b := block.(type) {
+	case *blocks.JumpTo:
+		targets = append(targets, index+int(b.Value))
+	case *blocks.CallSequence:
+		for _, offset := range b.Blocks {
+			targets = append(targets, index+int(int16(offset)))
+		}
+	case *blocks.Select:
+		for _, target := range b.Targets(index) {
+			targets = append(targets, target.Index)
+		}
+	}
+
+	return targets
+}
+
+// RemoveEmptyGroups deletes each GroupStart block that is immediately followed
+// by its GroupEnd, returning the number of groups removed. The offsets of any
+// flow control blocks are updated to account for the removed blocks.
+func (t *TZX) RemoveEmptyGroups() int {
+	groups := emptyGroups(t.blocks)
+
+	var indexes []int
+	for _, i := range groups {
+		indexes = append(indexes, i, i+1)
+	}
+	t.removeBlocks(indexes)
+
+	return len(groups)
+}
+
+// removeBlocks deletes the blocks at the given (ascending) indexes of the
+// tape blocks, then updates the relative offsets of the JumpTo, CallSequence,
+// and Select blocks so they still point to the same blocks. An offset to a
+// removed block will now point to the block that followed it.
+func (t *TZX) removeBlocks(indexes []int) {
+	if len(indexes) == 0 {
+		return
+	}
+
+	removed := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		removed[i] = true
+	}
+
+	// the new index of each block, once the blocks before it have been removed
+	newIndex := make([]int, len(t.blocks)+1)
+	count := 0
+	for i := range t.blocks {
+		newIndex[i] = i - count
+		if removed[i] {
+			count++
+		}
+	}
+	newIndex[len(t.blocks)] = len(t.blocks) - count
+
+	rebase := func(index, offset int) int {
+		target := index + offset
+		if target < 0 || target > len(t.blocks) {
+			return offset // leave invalid offsets unchanged
+		}
+		return newIndex[target] - newIndex[index]
+	}
+
+	var remaining []Block
+	for i, block := range t.blocks {
+		if removed[i] {
+			continue
+		}
+
+		switch b := block.(type) {
+		case *blocks.JumpTo:
+			b.Value = int16(rebase(i, int(b.Value)))
+		case *blocks.CallSequence:
+			for j, offset := range b.Blocks {
+				b.Blocks[j] = uint16(int16(rebase(i, int(int16(offset)))))
+			}
+		case *blocks.Select:
+			for j, s := range b.Selections {
+				b.Selections[j].RelativeOffset = int16(rebase(i, int(s.RelativeOffset)))
+			}
+		}
+
+		remaining = append(remaining, block)
+	}
+
+	t.setTapeBlocks(remaining)
+}
+
+// setTapeBlocks replaces the blocks of the tape.
+func (t *TZX) setTapeBlocks(tapeBlocks []Block) {
+	t.blocks = append([]Block(nil), tapeBlocks...)
+}
+
+// SetAllPauses sets every stand-alone and embedded pause on the tape to the
+// given duration, which is a quick way to standardize a compilation. Zero
+// duration pauses are left unchanged, as a stand-alone pause of 0 means
+// STOP THE TAPE, and a zero embedded pause must not change the pulse level.
+func (t *TZX) SetAllPauses(ms uint16) {
+	t.setPauses(ms, false)
+}
+
+// SetAllPausesIncludingZero sets every pause on the tape to the given
+// duration, as with SetAllPauses, but also forces zero duration pauses.
+func (t *TZX) SetAllPausesIncludingZero(ms uint16) {
+	t.setPauses(ms, true)
+}
+
+func (t *TZX) setPauses(ms uint16, includeZero bool) {
+	for _, block := range t.blocks {
+		b, ok := block.(interface{ SetPause(uint16) })
+		if !ok {
+			continue
+		}
+		if block.PauseMs() == 0 && !includeZero {
+			continue
+		}
+		b.SetPause(ms)
+	}
+}
+
+// CoalescePauses merges runs of adjacent stand-alone pause blocks into a
+// single pause of their total duration, returning the number of blocks
+// removed. Pauses longer than a single block allows are split across the
+// fewest blocks needed. Zero duration pauses (STOP THE TAPE) are not merged.
+func (t *TZX) CoalescePauses() int {
+	var remove []int
+	for i := 0; i < len(t.blocks); i++ {
+		var run []*blocks.PauseTapeCommand
+		for j := i; j < len(t.blocks); j++ {
+			pause, ok := t.blocks[j].(*blocks.PauseTapeCommand)
+			if !ok || pause.Pause == 0 {
+				break
+			}
+			run = append(run, pause)
+		}
+		if len(run) < 2 {
+			continue
+		}
+
+		var total uint32
+		for _, pause := range run {
+			total += uint32(pause.Pause)
+		}
+
+		merged := blocks.NewPauses(total)
+		for j, pause := range merged {
+			run[j].Pause = pause.Pause
+		}
+		for j := len(merged); j < len(run); j++ {
+			remove = append(remove, i+j)
+		}
+
+		i += len(run) - 1
+	}
+
+	t.removeBlocks(remove)
+
+	return len(remove)
+}
@@ -0,0 +1,210 @@
+package tzx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+func TestSliceKeepsInternalJumps(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x30, 0x02, 'h', 'i'}, // text description
+		[]byte{0x23, 0x02, 0x00},     // jump to the second standard block
+		standardBlock,
+		standardBlock,
+		[]byte{0x20, 0x00, 0x00}, // stop the tape
+	)
+	tape := readTape(t, raw)
+
+	slice, err := tape.Slice(1, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slice.Blocks()) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(slice.Blocks()))
+	}
+	if errs := slice.Validate(); len(errs) > 0 {
+		t.Errorf("unexpected validation errors: %v", errs)
+	}
+	if targets := flowTargets(0, slice.Blocks()[0]); !reflect.DeepEqual(targets, []int{2}) {
+		t.Errorf("expected the jump to target block 2, got %v", targets)
+	}
+
+	if _, err := tape.Slice(1, 3); err == nil {
+		t.Error("expected an error for a jump outside the slice")
+	}
+}
+
+func TestSliceRejectsCutFlowPairs(t *testing.T) {
+	loopStart := []byte{0x24, 0x02, 0x00}
+	loopEnd := []byte{0x25}
+	call := []byte{0x26, 0x01, 0x00, 0x02, 0x00} // calls the block after next
+	ret := []byte{0x27}
+	stop := []byte{0x20, 0x00, 0x00}
+
+	loopTape := readTape(t, tzxFile(20, loopStart, standardBlock, loopEnd, stop))
+	callTape := readTape(t, tzxFile(20, call, stop, standardBlock, ret, stop))
+
+	tests := []struct {
+		name       string
+		tape       *TZX
+		start, end int
+		want       string
+	}{
+		{"loop end outside", loopTape, 0, 2, "loop at block #01"},
+		{"loop start outside", loopTape, 1, 4, "loop end at block #03"},
+		{"return outside", callTape, 0, 3, "returns at block #04"},
+		{"call outside", callTape, 2, 5, "returns at block #04"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.tape.Slice(tt.start, tt.end)
+			if err == nil {
+				t.Fatal("expected an error for the cut flow control blocks")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected the error to contain %q, got: %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := loopTape.Slice(0, 3); err != nil {
+		t.Errorf("unexpected error for a whole loop: %v", err)
+	}
+	if _, err := callTape.Slice(0, 4); err != nil {
+		t.Errorf("unexpected error for a whole call sequence: %v", err)
+	}
+}
+
+func TestSliceCopiesBlocks(t *testing.T) {
+	text := []byte{0x30, 0x02, 'h', 'i'}
+	tape := readTape(t, tzxFile(20, text, standardBlock, []byte{0x20, 0x64, 0x00}))
+
+	slice, err := tape.Slice(0, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slice.SetAllPauses(500)
+	slice.Blocks()[0].(*blocks.TextDescription).Description[0] = 'H'
+
+	original := tape.Blocks()
+	if original[1].PauseMs() != 1000 || original[2].PauseMs() != 100 {
+		t.Errorf("expected the original pauses to be unchanged, got %d and %d", original[1].PauseMs(), original[2].PauseMs())
+	}
+	if desc := string(original[0].(*blocks.TextDescription).Description); desc != "hi" {
+		t.Errorf("expected the original description to be unchanged, got %q", desc)
+	}
+	if slice.Blocks()[1].PauseMs() != 500 {
+		t.Errorf("expected the slice pause to be 500, got %d", slice.Blocks()[1].PauseMs())
+	}
+}
+
+func TestRemoveEmptyGroups(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x23, 0x03, 0x00}, // jump to the second group
+		[]byte{0x21, 0x02, 'g', '1'},
+		[]byte{0x22},
+		[]byte{0x21, 0x02, 'g', '2'},
+		standardBlock,
+		[]byte{0x22},
+	)
+	tape := readTape(t, raw)
+
+	if removed := tape.RemoveEmptyGroups(); removed != 1 {
+		t.Errorf("expected 1 empty group to be removed, got %d", removed)
+	}
+
+	tapeBlocks := tape.Blocks()
+	if len(tapeBlocks) != 4 {
+		t.Fatalf("expected 4 blocks, got %d", len(tapeBlocks))
+	}
+	group, ok := tapeBlocks[1].(*blocks.GroupStart)
+	if !ok || string(group.GroupName) != "g2" {
+		t.Errorf("expected the second group to be kept, got %v", tapeBlocks[1])
+	}
+	if targets := flowTargets(0, tapeBlocks[0]); !reflect.DeepEqual(targets, []int{1}) {
+		t.Errorf("expected the jump to target block 1, got %v", targets)
+	}
+
+	if removed := tape.RemoveEmptyGroups(); removed != 0 {
+		t.Errorf("expected no more empty groups, got %d", removed)
+	}
+}
+
+func TestSetAllPauses(t *testing.T) {
+	noPause := []byte{0x10, 0x00, 0x00, 0x05, 0x00, 0xff, 0x01, 0x02, 0x03, 0xff}
+	raw := tzxFile(20,
+		standardBlock,
+		[]byte{0x20, 0xf4, 0x01}, // pause of 500ms
+		noPause,
+		[]byte{0x20, 0x00, 0x00}, // stop the tape
+		turboBlock(0xff, []byte{1, 2, 3}, 0xff),
+		[]byte{0x30, 0x02, 'h', 'i'},
+	)
+
+	tests := []struct {
+		name        string
+		includeZero bool
+		want        []uint16
+	}{
+		{"zero pauses unchanged", false, []uint16{250, 250, 0, 0, 250, 0}},
+		{"including zero pauses", true, []uint16{250, 250, 250, 250, 250, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := readTape(t, raw)
+			if tt.includeZero {
+				tape.SetAllPausesIncludingZero(250)
+			} else {
+				tape.SetAllPauses(250)
+			}
+
+			var pauses []uint16
+			for _, block := range tape.Blocks() {
+				pauses = append(pauses, block.PauseMs())
+			}
+			if !reflect.DeepEqual(pauses, tt.want) {
+				t.Errorf("expected pauses of %v, got %v", tt.want, pauses)
+			}
+		})
+	}
+}
+
+func TestCoalescePauses(t *testing.T) {
+	pause := func(ms uint16) []byte { return []byte{0x20, byte(ms), byte(ms >> 8)} }
+
+	raw := tzxFile(20,
+		standardBlock,
+		pause(500),
+		pause(700),
+		standardBlock,
+		pause(40000),
+		pause(40000),
+		pause(1000),
+		pause(0), // stop the tape
+		pause(100),
+	)
+	tape := readTape(t, raw)
+
+	if merged := tape.CoalescePauses(); merged != 2 {
+		t.Errorf("expected 2 pause blocks to be merged, got %d", merged)
+	}
+
+	var pauses []uint16
+	for _, block := range tape.Blocks() {
+		if p, ok := block.(*blocks.PauseTapeCommand); ok {
+			pauses = append(pauses, p.Pause)
+		}
+	}
+	if want := []uint16{1200, 65535, 81000 - 65535, 0, 100}; !reflect.DeepEqual(pauses, want) {
+		t.Errorf("expected pauses of %v, got %v", want, pauses)
+	}
+	if len(tape.Blocks()) != 7 {
+		t.Errorf("expected 7 blocks, got %d", len(tape.Blocks()))
+	}
+}
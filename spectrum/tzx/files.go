@@ -0,0 +1,194 @@
+package tzx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mrcook/retroio/spectrum/basic"
+	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tap/headers"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// tapeFile is a Spectrum file found on the tape, made up of a standard
+// header block, and the data block that follows it.
+type tapeFile struct {
+	Name        string    // Filename, with any trailing spaces removed
+	Header      tap.Block // ROM header
+	HeaderIndex int       // Index of the header block
+	DataIndex   int       // Index of the data block
+	Data        []byte    // File data, without the flag and checksum bytes
+}
+
+// files returns each file found on the tape, where a StandardSpeedData header
+// block is followed by a StandardSpeedData or TurboSpeedData data block.
+// Block indexes start from 0 and include any ArchiveInfo block.
+func (t TZX) files() []tapeFile {
+	var files []tapeFile
+
+	for i := 0; i < len(t.blocks)-1; i++ {
+		header := t.blocks[i].BlockData()
+		if header == nil || header.Filename() == "" {
+			continue
+		}
+
+		// skip any non-data blocks (text, pauses, etc.) between the header and data
+		for j := i + 1; j < len(t.blocks); j++ {
+			data, ok := fileData(t.blocks[j])
+			if !ok {
+				continue
+			}
+			if next := t.blocks[j].BlockData(); next != nil && next.Filename() != "" {
+				break // a header without its data
+			}
+
+			files = append(files, tapeFile{
+				Name:        strings.TrimRight(header.Filename(), " "),
+				Header:      header,
+				HeaderIndex: i,
+				DataIndex:   j,
+				Data:        data,
+			})
+			i = j
+			break
+		}
+	}
+
+	return files
+}
+
+// fileData returns the data of a standard or turbo data block, without the
+// flag and checksum bytes.
+func fileData(block Block) ([]byte, bool) {
+	var data []byte
+
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		data = b.Bytes()
+	case *blocks.TurboSpeedData:
+		data = b.DataBlock
+	default:
+		return nil, false
+	}
+
+	if len(data) < 2 {
+		return nil, true
+	}
+	return data[1 : len(data)-1], true
+}
+
+// FileHashes returns the SHA-1 hash of the data of each Spectrum file found on
+// the tape, keyed by filename, which can be used to find the same program on
+// different tapes. Duplicate filenames are given a numbered suffix, e.g. "name (2)".
+func (t TZX) FileHashes() map[string]string {
+	hashes := make(map[string]string)
+	seen := make(map[string]int)
+
+	for _, file := range t.files() {
+		name := file.Name
+		seen[file.Name]++
+		if seen[file.Name] > 1 {
+			name = fmt.Sprintf("%s (%d)", file.Name, seen[file.Name])
+		}
+
+		sum := sha1.Sum(file.Data)
+		hashes[name] = hex.EncodeToString(sum[:])
+	}
+
+	return hashes
+}
+
+// Fingerprint returns a SHA-1 hash identifying the tape by its content, so
+// that tapes differing only in their metadata can be matched. The hash is of
+// the payloads of the data blocks, concatenated in file order:
+//   - Standard Speed Data: the data bytes, including the flag and checksum
+//   - Turbo Speed Data and Pure Data: the data bytes
+//   - Direct Recording: the sample bytes
+//   - CSW Recording: the stored (possibly compressed) pulse data
+//   - Generalized Data: the data stream bytes
+//
+// Timings, pauses, and all other blocks (text, archive info, flow control,
+// etc.) are not included.
+func (t TZX) Fingerprint() string {
+	hash := sha1.New()
+
+	for _, block := range t.DataBlocks() {
+		switch b := block.(type) {
+		case *blocks.StandardSpeedData:
+			hash.Write(b.Bytes())
+		case *blocks.TurboSpeedData:
+			hash.Write(b.DataBlock)
+		case *blocks.PureData:
+			hash.Write(b.DataBlock)
+		case *blocks.DirectRecording:
+			hash.Write(b.Data)
+		case *blocks.CswRecording:
+			hash.Write(b.Data)
+		case *blocks.GeneralizedData:
+			hash.Write(b.DataStreams)
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ListBASIC returns a listing of each BASIC program on the tape, decoded from
+// the data block following each Program header. Only the program itself is
+// listed, as given by the program length in the header; any variables saved
+// after it are skipped.
+//
+// A program that can not be decoded, or whose data block is shorter than the
+// program length, is listed with the reason in place of its lines, and the
+// remaining programs are still listed. The listing is then returned along
+// with an error naming each of these programs.
+func (t TZX) ListBASIC() (string, error) {
+	var listing string
+	var failed []string
+
+	for _, file := range t.files() {
+		header, ok := file.Header.(*headers.ProgramData)
+		if !ok {
+			continue
+		}
+
+		listing += fmt.Sprintf("BLK#%02d: %s\n", file.DataIndex+1, file.Name)
+
+		var program []string
+		var err error
+		if len(file.Data) < int(header.ProgramLength) {
+			err = fmt.Errorf("program is truncated, expected %d bytes, got %d", header.ProgramLength, len(file.Data))
+		} else {
+			program, err = basic.Decode(file.Data[:header.ProgramLength])
+		}
+		if err != nil {
+			listing += fmt.Sprintf("    %s\n\n", err)
+			failed = append(failed, fmt.Sprintf("'%s' (block #%02d): %s", file.Name, file.DataIndex+1, err))
+			continue
+		}
+
+		for _, line := range program {
+			listing += line
+		}
+		listing += "\n"
+	}
+
+	if len(failed) > 0 {
+		return listing, fmt.Errorf("unable to decode BASIC programs %s", strings.Join(failed, ", "))
+	}
+	return listing, nil
+}
+
+// LoadingScreenIndex returns the index (starting from 0, and including any
+// ArchiveInfo block) of the first data block containing a loading screen,
+// i.e. a 6912 byte CODE file loaded at address 16384, as given in its header.
+func (t TZX) LoadingScreenIndex() (int, bool) {
+	for _, file := range t.files() {
+		header, ok := file.Header.(*headers.ByteData)
+		if ok && header.DataLength == 6912 && header.StartAddress == 16384 {
+			return file.DataIndex, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,119 @@
+package tzx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// programHeader returns a standard header block for a BASIC program of the
+// given length, with no variables.
+func programHeader(name string, length uint16) []byte {
+	data := []byte{0x00}
+	data = append(data, []byte(name + "          ")[:10]...)
+	data = append(data, byte(length), byte(length>>8), 0x00, 0x80, byte(length), byte(length>>8))
+	return standardTAPBlock(0x00, data)
+}
+
+func TestListBASIC(t *testing.T) {
+	program := []byte{0x00, 0x0a, 0x03, 0x00, 0xef, 0x22, 0x0d} // 10 LOAD "
+
+	raw := tzxFile(20,
+		programHeader("broken", 20),
+		standardTAPBlock(0xff, program),
+		programHeader("loader", uint16(len(program))),
+		standardTAPBlock(0xff, program),
+	)
+	listing, err := readTape(t, raw).ListBASIC()
+
+	if err == nil {
+		t.Error("expected an error for the truncated program")
+	} else if !strings.Contains(err.Error(), "'broken' (block #02)") {
+		t.Errorf("expected the error to name the truncated program, got: %v", err)
+	}
+
+	want := "BLK#02: broken\n" +
+		"    program is truncated, expected 20 bytes, got 7\n\n" +
+		"BLK#04: loader\n" +
+		"  10  LOAD \"\n\n"
+	if listing != want {
+		t.Errorf("expected listing:\n%q\ngot:\n%q", want, listing)
+	}
+}
+
+func TestFileHashes(t *testing.T) {
+	loader := []byte{0x00, 0x0a, 0x03, 0x00, 0xef, 0x22, 0x0d}
+	game := []byte{0x01, 0x02, 0x03, 0x04}
+	patched := []byte{0x01, 0x02, 0x03, 0x05}
+
+	raw := tzxFile(20,
+		programHeader("loader", uint16(len(loader))),
+		standardTAPBlock(0xff, loader),
+		codeHeader("game", uint16(len(game))),
+		standardTAPBlock(0xff, game),
+		codeHeader("game", uint16(len(patched))),
+		standardTAPBlock(0xff, patched),
+	)
+
+	hash := func(data []byte) string {
+		sum := sha1.Sum(data)
+		return hex.EncodeToString(sum[:])
+	}
+	want := map[string]string{
+		"loader":   hash(loader),
+		"game":     hash(game),
+		"game (2)": hash(patched),
+	}
+	if hashes := readTape(t, raw).FileHashes(); !reflect.DeepEqual(hashes, want) {
+		t.Errorf("expected hashes %v, got %v", want, hashes)
+	}
+}
+
+func TestLoadingScreenIndex(t *testing.T) {
+	loader := []byte{0x00, 0x0a, 0x03, 0x00, 0xef, 0x22, 0x0d}
+	screen := make([]byte, 6912)
+
+	screenHeader := []byte{0x03}
+	screenHeader = append(screenHeader, "screen    "...)
+	screenHeader = append(screenHeader, 0x00, 0x1b, 0x00, 0x40, 0x00, 0x80) // 6912 bytes at 16384
+
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		want   int
+		found  bool
+	}{
+		{
+			"with a screen",
+			[][]byte{
+				archiveTitle("Game"),
+				programHeader("loader", uint16(len(loader))),
+				standardTAPBlock(0xff, loader),
+				standardTAPBlock(0x00, screenHeader),
+				standardTAPBlock(0xff, screen),
+			},
+			4, true,
+		},
+		{
+			"6912 bytes loaded elsewhere",
+			[][]byte{
+				programHeader("loader", uint16(len(loader))),
+				standardTAPBlock(0xff, loader),
+				codeHeader("code", uint16(len(screen))),
+				standardTAPBlock(0xff, screen),
+			},
+			0, false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, found := readTape(t, tzxFile(20, tt.blocks...)).LoadingScreenIndex()
+			if index != tt.want || found != tt.found {
+				t.Errorf("expected index %d (%t), got %d (%t)", tt.want, tt.found, index, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package tzx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonTape is the JSON representation of the tape metadata.
+type jsonTape struct {
+	Version string            `json:"version"`
+	Archive []WebArchiveEntry `json:"archive,omitempty"`
+	Blocks  []jsonBlock       `json:"blocks"`
+}
+
+// jsonBlock contains the ID and name of a block, along with the fields of
+// the block type, as given by its struct.
+type jsonBlock struct {
+	ID     uint8  `json:"id"`
+	Name   string `json:"name"`
+	Fields Block  `json:"fields"`
+}
+
+// MarshalJSON returns the tape version, archive info, and every block as
+// JSON. Each block includes its ID and name, and all the fields of the block
+// type, where byte slices are encoded as base64 strings.
+func (t TZX) MarshalJSON() ([]byte, error) {
+	tape := jsonTape{
+		Version: fmt.Sprintf("%d.%d", t.MajorVersion, t.MinorVersion),
+		Archive: t.webArchiveEntries(),
+		Blocks:  []jsonBlock{},
+	}
+
+	for _, block := range t.blocks {
+		tape.Blocks = append(tape.Blocks, jsonBlock{
+			ID:     uint8(block.Id()),
+			Name:   block.Name(),
+			Fields: block,
+		})
+	}
+
+	return json.Marshal(tape)
+}
@@ -0,0 +1,79 @@
+package tzx
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// PulseState is the 'current pulse level' of the tape signal, which is the
+// level that the next pulse will be played at (true for high, false for low).
+// It is carried from one block to the next, so that the edges are correct
+// where blocks join. The zero value is the low level that playback starts at.
+type PulseState struct {
+	Level bool
+}
+
+// Update sets the state to the level after playing the block, following
+// the TZX rules described for PulseLevels.
+func (s *PulseState) Update(block Block) {
+	s.Level = pulseLevelAfter(block, s.Level)
+}
+
+// PulseLevels returns the 'current pulse level' after playing each block on
+// the tape (true for high, false for low), which is the level that the next
+// pulse will be played at. Following the TZX rules:
+//   - playback starts at a low level.
+//   - each pulse toggles the level, so that the next pulse produces an edge.
+//   - each Generalized Data symbol first sets the level given by its
+//     polarity flags: an edge, no edge, or a forced low or high level.
+//   - Direct and CSW recordings leave the level at the last level played.
+//   - a pause of some duration, either stand-alone or embedded in a data
+//     block, finishes at a low level. A zero duration pause is completely
+//     ignored, so the level is NOT changed.
+//   - a Pause block of 0 stops the tape, which also leaves the level low.
+//
+// Flow control blocks are not followed; levels are given in file order.
+func (t TZX) PulseLevels() []bool {
+	levels := make([]bool, len(t.blocks))
+
+	var state PulseState
+	for i, block := range t.blocks {
+		state.Update(block)
+		levels[i] = state.Level
+	}
+
+	return levels
+}
+
+// pulseLevelAfter returns the current pulse level after playing the block,
+// given the level before it.
+func pulseLevelAfter(block Block, level bool) bool {
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData, *blocks.TurboSpeedData, *blocks.PureTone, *blocks.SequenceOfPulses, *blocks.PureData:
+		if blockPulseCount(block)%2 == 1 {
+			level = !level
+		}
+	case *blocks.DirectRecording:
+		if samples := b.Samples(); len(samples) > 0 {
+			level = samples[len(samples)-1]
+		}
+	case *blocks.GeneralizedData:
+		_, _, level = generalizedDataPulses(b, level)
+	case *blocks.CswRecording:
+		// the last pulse was played at the opposite level of the one it left
+		if b.StoredPulseCount > 0 && b.StoredPulseCount%2 == 0 {
+			level = !level
+		}
+	case *blocks.SetSignalLevel:
+		level = b.SignalLevel == 1
+	case *blocks.PauseTapeCommand:
+		if b.IsStopTheTape() {
+			level = false
+		}
+	}
+
+	if block.PauseMs() > 0 {
+		level = false
+	}
+
+	return level
+}
@@ -0,0 +1,103 @@
+package tzx
+
+import (
+	"testing"
+
+	tapblocks "github.com/mrcook/retroio/spectrum/tap/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// gdbBlock returns a GeneralizedData block playing a single data symbol of
+// one pulse, with the given polarity flags.
+func gdbBlock(flags uint8, pause uint16) *blocks.GeneralizedData {
+	return &blocks.GeneralizedData{
+		BlockID:     types.GeneralizedData,
+		Pause:       pause,
+		TOTD:        1,
+		NPD:         2,
+		ASD:         2,
+		DataSymbols: []blocks.Symbol{{Flags: flags, PulseLengths: []uint16{855, 0}}, {PulseLengths: []uint16{1710, 0}}},
+		DataStreams: []byte{0x00},
+	}
+}
+
+func TestPulseLevelAfterEachBlock(t *testing.T) {
+	header := &tapblocks.Fragment{Length: 1, Data: []byte{0x00}}
+
+	tests := []struct {
+		name  string
+		block Block
+		start bool
+		want  bool
+	}{
+		// blocks 10/11/12/13/14/19: each pulse toggles the level
+		{"standard speed data", blocks.NewStandardSpeedData(1, header, 0), false, true},
+		{"standard speed data with pause", blocks.NewStandardSpeedData(1, header, 1000), false, false},
+		{"turbo data, even pulses", &blocks.TurboSpeedData{BlockID: types.TurboSpeedData, PilotTone: 10, UsedBits: 8, DataBlock: []byte{0x55}}, true, true},
+		{"turbo data, odd pulses", &blocks.TurboSpeedData{BlockID: types.TurboSpeedData, PilotTone: 11, UsedBits: 8, DataBlock: []byte{0x55}}, true, false},
+		{"pure tone, odd pulses", &blocks.PureTone{BlockID: types.PureTone, Length: 2168, PulseCount: 3}, false, true},
+		{"pure tone, even pulses", &blocks.PureTone{BlockID: types.PureTone, Length: 2168, PulseCount: 2}, false, false},
+		{"sequence of pulses", &blocks.SequenceOfPulses{BlockID: types.SequenceOfPulses, Count: 1, Lengths: []uint16{667}}, true, false},
+		{"pure data", &blocks.PureData{BlockID: types.PureData, UsedBits: 3, DataBlock: []byte{0xa0}}, true, true},
+		{"generalized data, edge", gdbBlock(0x00, 0), false, true},
+		{"generalized data, no edge", gdbBlock(0x01, 0), false, false},
+		{"generalized data, force low", gdbBlock(0x02, 0), true, true},
+		{"generalized data, force high", gdbBlock(0x03, 0), true, false},
+		{"generalized data with pause", gdbBlock(0x00, 100), false, false},
+
+		// Direct and CSW recordings: the last level played
+		{"direct recording, high", &blocks.DirectRecording{BlockID: types.DirectRecording, TStatesPerSample: 79, UsedBits: 1, Data: []byte{0x80}}, false, true},
+		{"direct recording, low", &blocks.DirectRecording{BlockID: types.DirectRecording, TStatesPerSample: 79, UsedBits: 8, Data: []byte{0x01, 0x00}}, true, false},
+		{"csw recording, even pulses", &blocks.CswRecording{BlockID: types.CswRecording, SampleRate: 44100, CompressionType: 1, StoredPulseCount: 2, Data: []byte{10, 10}}, false, true},
+		{"csw recording, odd pulses", &blocks.CswRecording{BlockID: types.CswRecording, SampleRate: 44100, CompressionType: 1, StoredPulseCount: 3, Data: []byte{10, 10, 10}}, false, false},
+
+		// Set Signal Level and pauses: a specific level
+		{"set signal level high", &blocks.SetSignalLevel{BlockID: types.SetSignalLevel, SignalLevel: 1}, false, true},
+		{"set signal level low", &blocks.SetSignalLevel{BlockID: types.SetSignalLevel, SignalLevel: 0}, true, false},
+		{"pause", blocks.NewPause(500), true, false},
+		{"stop the tape", blocks.NewPause(0), true, false},
+
+		// blocks without a signal leave the level unchanged
+		{"text description", &blocks.TextDescription{BlockID: types.TextDescription}, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := PulseState{Level: tt.start}
+			state.Update(tt.block)
+			if state.Level != tt.want {
+				t.Errorf("PulseState.Update: expected level %v, got %v", tt.want, state.Level)
+			}
+
+			// the renderer must agree with the level model
+			_, level, err := RenderBlock(tt.block, 44100, tt.start)
+			if err != nil {
+				t.Fatalf("RenderBlock: unexpected error: %v", err)
+			}
+			if level != tt.want {
+				t.Errorf("RenderBlock: expected level %v, got %v", tt.want, level)
+			}
+		})
+	}
+}
+
+func TestGeneralizedDataPulseCount(t *testing.T) {
+	g := &blocks.GeneralizedData{
+		BlockID:      types.GeneralizedData,
+		TOTP:         2,
+		NPP:          2,
+		ASP:          2,
+		PilotSymbols: []blocks.Symbol{{PulseLengths: []uint16{2168, 0}}, {PulseLengths: []uint16{667, 735}}},
+		PilotStreams: []blocks.PilotRLE{{Symbol: 0, RepetitionCount: 10}, {Symbol: 1, RepetitionCount: 1}},
+		TOTD:         8,
+		NPD:          2,
+		ASD:          2,
+		DataSymbols:  []blocks.Symbol{{PulseLengths: []uint16{855, 855}}, {PulseLengths: []uint16{1710, 1710}}},
+		DataStreams:  []byte{0xa5},
+	}
+
+	if count := blockPulseCount(g); count != 10+2+16 {
+		t.Errorf("expected %d pulses, got %d", 10+2+16, count)
+	}
+}
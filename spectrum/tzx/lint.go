@@ -0,0 +1,168 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// Lint checks the tape for block sequences which can be read correctly, but
+// which are likely to cause problems during playback. A description of each
+// problem found is returned, referencing the block numbers (starting from 1).
+func (t TZX) Lint() []string {
+	var warnings []string
+	warnings = append(warnings, lintLoopCallOverlaps(t.blocks)...)
+	warnings = append(warnings, lintMissingFinalPause(t.blocks)...)
+	warnings = append(warnings, lintEmptyGroups(t.blocks)...)
+	warnings = append(warnings, lintTurboLengthHighByte(t.blocks)...)
+	warnings = append(warnings, lintGlueSignatures(t.blocks)...)
+
+	return warnings
+}
+
+// lintMissingFinalPause reports a tape where the last block producing a
+// signal has no pause, and is not followed by a pause or stop block, as the
+// last edge will not be properly finished. Blocks without a signal, such as
+// text, groups and archive info, may follow it. The spec recommends including
+// a pause after each sequence of blocks.
+func lintMissingFinalPause(tapeBlocks []Block) []string {
+	for i := len(tapeBlocks) - 1; i >= 0; i-- {
+		block := tapeBlocks[i]
+		if block.Id() == types.PauseTapeCommand {
+			return nil
+		}
+		if blockPulseCount(block) == 0 {
+			continue
+		}
+
+		if block.PauseMs() == 0 {
+			return []string{fmt.Sprintf("final %s block #%02d has no pause, and is not followed by a pause or stop block", block.Name(), i+1)}
+		}
+		return nil
+	}
+	return nil
+}
+
+// lintEmptyGroups reports any GroupStart block that is immediately followed
+// by a GroupEnd, as the empty group only adds noise.
+func lintEmptyGroups(tapeBlocks []Block) []string {
+	var warnings []string
+	for _, i := range emptyGroups(tapeBlocks) {
+		warnings = append(warnings, fmt.Sprintf("empty group at blocks #%02d-#%02d", i+1, i+2))
+	}
+	return warnings
+}
+
+// lintTurboLengthHighByte reports turbo data blocks of 64K or more, where the
+// third byte of the length is non-zero. Some tools read the length as a WORD
+// followed by a spare byte, and so would misread these blocks.
+func lintTurboLengthHighByte(tapeBlocks []Block) []string {
+	var warnings []string
+	for i, block := range tapeBlocks {
+		turbo, ok := block.(*blocks.TurboSpeedData)
+		if !ok || turbo.Length[2] == 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"turbo data block #%02d has a length of %d bytes, which will be misread by tools using a 2-byte length",
+			i+1, len(turbo.DataBlock),
+		))
+	}
+	return warnings
+}
+
+// lintGlueSignatures reports glue blocks without the `XTape!` signature,
+// which suggests the appended file is damaged or not a TZX file.
+func lintGlueSignatures(tapeBlocks []Block) []string {
+	var warnings []string
+	for i, block := range tapeBlocks {
+		if glue, ok := block.(*blocks.GlueBlock); ok && !glue.SignatureValid() {
+			warnings = append(warnings, fmt.Sprintf("glue block #%02d has an invalid signature", i+1))
+		}
+	}
+	return warnings
+}
+
+// emptyGroups returns the index of each GroupStart immediately followed by a GroupEnd.
+func emptyGroups(tapeBlocks []Block) []int {
+	var groups []int
+	for i := 0; i < len(tapeBlocks)-1; i++ {
+		if tapeBlocks[i].Id() == types.GroupStart && tapeBlocks[i+1].Id() == types.GroupEnd {
+			groups = append(groups, i)
+		}
+	}
+	return groups
+}
+
+// blockRegion is an inclusive range of block indexes.
+type blockRegion struct {
+	start, end int
+}
+
+func (r blockRegion) contains(i int) bool {
+	return i >= r.start && i <= r.end
+}
+
+// lintLoopCallOverlaps reports call sequences that jump into, or out of, a
+// loop, and called sequences that only partially overlap a loop. Unclosed
+// loops are treated as running to the end of the tape.
+func lintLoopCallOverlaps(tapeBlocks []Block) []string {
+	var warnings []string
+
+	var loops []blockRegion
+	var openLoops []int
+	for i, block := range tapeBlocks {
+		switch block.Id() {
+		case types.LoopStart:
+			openLoops = append(openLoops, i)
+		case types.LoopEnd:
+			if len(openLoops) > 0 {
+				loops = append(loops, blockRegion{start: openLoops[len(openLoops)-1], end: i})
+				openLoops = openLoops[:len(openLoops)-1]
+			}
+		}
+	}
+	for _, start := range openLoops {
+		loops = append(loops, blockRegion{start: start, end: len(tapeBlocks) - 1})
+	}
+
+	for i, block := range tapeBlocks {
+		call, ok := block.(*blocks.CallSequence)
+		if !ok {
+			continue
+		}
+
+		for _, offset := range call.Blocks {
+			target := i + int(int16(offset))
+			if target < 0 || target >= len(tapeBlocks) {
+				continue
+			}
+
+			// the called sequence runs until the next return block
+			sequence := blockRegion{start: target, end: len(tapeBlocks) - 1}
+			for j := target; j < len(tapeBlocks); j++ {
+				if tapeBlocks[j].Id() == types.ReturnFromSequence {
+					sequence.end = j
+					break
+				}
+			}
+
+			for _, loop := range loops {
+				if loop.contains(i) != loop.contains(target) {
+					warnings = append(warnings, fmt.Sprintf(
+						"call sequence at block #%02d targets block #%02d across the loop at blocks #%02d-#%02d",
+						i+1, target+1, loop.start+1, loop.end+1,
+					))
+				} else if loop.contains(sequence.start) != loop.contains(sequence.end) {
+					warnings = append(warnings, fmt.Sprintf(
+						"called sequence at blocks #%02d-#%02d overlaps the loop at blocks #%02d-#%02d",
+						sequence.start+1, sequence.end+1, loop.start+1, loop.end+1,
+					))
+				}
+			}
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,110 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintMissingFinalPause(t *testing.T) {
+	noPause := []byte{0x10, 0x00, 0x00, 0x05, 0x00, 0xff, 0x01, 0x02, 0x03, 0xff}
+	pause := []byte{0x20, 0xe8, 0x03}
+	stop := []byte{0x20, 0x00, 0x00}
+	text := []byte{0x30, 0x02, 'h', 'i'}
+	groupStart := []byte{0x21, 0x02, 'g', '1'}
+	groupEnd := []byte{0x22}
+	archive := []byte{0x32, 0x05, 0x00, 0x01, 0x00, 0x02, 'h', 'i'}
+
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		want   bool
+	}{
+		{"ends with a zero pause data block", [][]byte{standardBlock, noPause}, true},
+		{"zero pause data block followed by a group end", [][]byte{groupStart, noPause, groupEnd}, true},
+		{"zero pause data block followed by text and archive info", [][]byte{noPause, text, archive}, true},
+		{"zero pause data block followed by a pause", [][]byte{noPause, pause, text}, false},
+		{"zero pause data block followed by a stop", [][]byte{noPause, stop}, false},
+		{"ends with a data block with a pause", [][]byte{noPause, standardBlock, groupEnd}, false},
+		{"no signal blocks", [][]byte{text}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := readTape(t, tzxFile(20, tt.blocks...))
+
+			warnings := lintMissingFinalPause(tape.Blocks())
+			if got := len(warnings) > 0; got != tt.want {
+				t.Errorf("expected a warning: %v, got %q", tt.want, warnings)
+			}
+		})
+	}
+}
+
+func TestLintLoopCallOverlaps(t *testing.T) {
+	call := []byte{0x26, 0x01, 0x00, 0x02, 0x00} // calls the block after next
+	ret := []byte{0x27}
+	loopStart := []byte{0x24, 0x02, 0x00}
+	loopEnd := []byte{0x25}
+	stop := []byte{0x20, 0x00, 0x00}
+	tone := pureTone(2168, 10)
+
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		want   []string
+	}{
+		{
+			"call into a loop",
+			[][]byte{call, stop, loopStart, tone, ret, loopEnd},
+			[]string{"call sequence at block #01 targets block #03 across the loop at blocks #03-#06"},
+		},
+		{
+			"called sequence ending inside a loop",
+			[][]byte{call, stop, tone, loopStart, ret, tone, loopEnd},
+			[]string{"called sequence at blocks #03-#05 overlaps the loop at blocks #04-#07"},
+		},
+		{
+			"called sequence beside a loop",
+			[][]byte{call, stop, tone, ret, loopStart, tone, loopEnd},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := readTape(t, tzxFile(20, tt.blocks...))
+
+			if got := lintLoopCallOverlaps(tape.Blocks()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected warnings %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLintEmptyGroups(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x21, 0x02, 'g', '1'},
+		[]byte{0x22},
+		[]byte{0x21, 0x02, 'g', '2'},
+		standardBlock,
+		[]byte{0x22},
+	)
+
+	warnings := lintEmptyGroups(readTape(t, raw).Blocks())
+	if want := []string{"empty group at blocks #01-#02"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("expected %q, got %q", want, warnings)
+	}
+}
+
+func TestLintTurboLengthHighByte(t *testing.T) {
+	raw := tzxFile(20,
+		turboBlock(0xff, []byte{1, 2, 3}, 0xff),
+		turboBlock(0xff, make([]byte, 0x10000), 0xff),
+	)
+	tape := readTape(t, raw)
+
+	want := []string{"turbo data block #02 has a length of 65538 bytes, which will be misread by tools using a 2-byte length"}
+	if warnings := lintTurboLengthHighByte(tape.Blocks()); !reflect.DeepEqual(warnings, want) {
+		t.Errorf("expected %q, got %q", want, warnings)
+	}
+}
@@ -0,0 +1,103 @@
+package tzx
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// PulseOffset maps a position in the played tape signal to the source of the
+// data in the TZX file.
+type PulseOffset struct {
+	TState     uint64 // Position from the start of the tape, in T-states
+	BlockIndex int    // Index of the block (starting from 0, including any ArchiveInfo block)
+	FileOffset int64  // Offset of the byte in the TZX file, or -1 when unknown
+}
+
+// PulseOffsetMap returns the T-state position at which each block starts
+// playing, and for data blocks, the position of each data byte. This allows
+// a position in a rendered waveform to be mapped back to a byte in the file.
+// Pauses are included in the positions, but flow control blocks are not
+// followed, so the tape is mapped in file order.
+//
+// File offsets are only available for tapes read from a file, as they are
+// taken from the block index recorded while reading.
+func (t TZX) PulseOffsetMap() []PulseOffset {
+	var offsets []PulseOffset
+
+	hasIndex := len(t.index) == len(t.blocks)
+
+	var position uint64
+	for i, block := range t.blocks {
+		blockOffset := int64(-1)
+		if hasIndex {
+			blockOffset = t.index[i].Offset
+		}
+		offsets = append(offsets, PulseOffset{TState: position, BlockIndex: i, FileOffset: blockOffset})
+
+		// the offset of the first data byte within the block, and the timing of each bit
+		var data []byte
+		var dataOffset int64
+		var usedBits uint8 = 8
+		var zeroBitPulse, oneBitPulse uint16
+		var dataStart uint64
+
+		switch b := block.(type) {
+		case *blocks.StandardSpeedData:
+			data, dataOffset = b.Bytes(), 5
+			zeroBitPulse, oneBitPulse = blocks.StandardZeroBitPulse, blocks.StandardOneBitPulse
+			dataStart = uint64(b.PilotDurationTStates())
+		case *blocks.TurboSpeedData:
+			data, dataOffset, usedBits = b.DataBlock, 19, b.UsedBits
+			zeroBitPulse, oneBitPulse = b.ZeroBitPulse, b.OneBitPulse
+			dataStart = uint64(b.PilotDurationTStates())
+		case *blocks.PureData:
+			data, dataOffset, usedBits = b.DataBlock, 11, b.UsedBits
+			zeroBitPulse, oneBitPulse = b.ZeroBitPulse, b.OneBitPulse
+		}
+
+		bytePosition := position + dataStart
+		for j := range data {
+			fileOffset := int64(-1)
+			if hasIndex {
+				fileOffset = blockOffset + dataOffset + int64(j)
+			}
+			offsets = append(offsets, PulseOffset{TState: bytePosition, BlockIndex: i, FileOffset: fileOffset})
+
+			bits := uint8(8)
+			if j == len(data)-1 {
+				bits = usedBits
+			}
+			bytePosition += blocks.DataTStates(data[j:j+1], bits, zeroBitPulse, oneBitPulse)
+		}
+
+		tStates, pause := blockTiming(block)
+		position += tStates + uint64(pause)*blocks.TStatesPerSecond/1000
+	}
+
+	return offsets
+}
+
+// DisplayOffsets prints a table of the blocks, showing the position and size
+// of each block in the TZX file, as recorded while the blocks were read.
+func (t TZX) DisplayOffsets() {
+	if len(t.index) != len(t.blocks) {
+		fmt.Println("Block offsets are only available for tapes read from a file.")
+		return
+	}
+
+	// the name column is as wide as the longest name, including custom names
+	nameWidth := len("Name")
+	for _, block := range t.blocks {
+		if width := utf8.RuneCountInString(block.Name()); width > nameWidth {
+			nameWidth = width
+		}
+	}
+
+	fmt.Printf("%-5s  %-4s  %-*s  %10s  %8s\n", "Block", "ID", nameWidth, "Name", "Offset", "Size")
+	for i, block := range t.blocks {
+		info := t.index[i]
+		fmt.Printf("#%-4d  0x%02X  %-*s  0x%08X  %8d\n", i+1, uint8(info.ID), nameWidth, block.Name(), info.Offset, info.Size)
+	}
+}
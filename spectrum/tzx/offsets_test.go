@@ -0,0 +1,26 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+func TestPulseOffsetMap(t *testing.T) {
+	pureData := []byte{0x14, 0x57, 0x03, 0xae, 0x06, 0x08, 0x00, 0x00, 0x02, 0x00, 0x00, 0xff, 0x00}
+	tape := readTape(t, tzxFile(20, pureTone(1000, 4), pureData))
+
+	// the first byte is eight one bits, each of two pulses
+	oneBitPulse := uint64(tape.Blocks()[1].(*blocks.PureData).OneBitPulse)
+
+	want := []PulseOffset{
+		{TState: 0, BlockIndex: 0, FileOffset: 10},
+		{TState: 4000, BlockIndex: 1, FileOffset: 15},
+		{TState: 4000, BlockIndex: 1, FileOffset: 26},                  // 0xff
+		{TState: 4000 + 16*oneBitPulse, BlockIndex: 1, FileOffset: 27}, // 0x00
+	}
+	if offsets := tape.PulseOffsetMap(); !reflect.DeepEqual(offsets, want) {
+		t.Errorf("unexpected pulse offsets\nexpected: %+v\ngot:      %+v", want, offsets)
+	}
+}
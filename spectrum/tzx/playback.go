@@ -0,0 +1,305 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// defaultMaxPlaybackSteps limits the number of blocks played when following
+// the flow control blocks, protecting against tapes with infinite loops.
+const defaultMaxPlaybackSteps = 1 << 20
+
+// maxPlaybackSteps returns the step limit set in the reader options, or the default.
+func (t TZX) maxPlaybackSteps() int {
+	if t.options.MaxPlaybackSteps > 0 {
+		return t.options.MaxPlaybackSteps
+	}
+	return defaultMaxPlaybackSteps
+}
+
+// LinearBlocks returns the blocks in the order a player would play them,
+// jumping, repeating loops, and following call sequences. The JumpTo, loop,
+// and call sequence blocks are interpreted, so are not included. Select
+// blocks offer a choice to the user, so play continues to the next block.
+//
+// An error is returned if a flow control block is invalid, or the number of
+// blocks played exceeds the MaxPlaybackSteps option.
+func (t TZX) LinearBlocks() ([]Block, error) {
+	order, err := playbackOrder(t.blocks, 0, t.maxPlaybackSteps())
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Block
+	for _, i := range order {
+		switch t.blocks[i].Id() {
+		case types.JumpTo, types.LoopStart, types.LoopEnd, types.CallSequence, types.ReturnFromSequence:
+		default:
+			list = append(list, t.blocks[i])
+		}
+	}
+
+	return list, nil
+}
+
+// BlocksToReach returns the blocks that must be played, in order, to load the
+// file with the given index (starting from 0), where a file is a standard
+// header followed by its data block. Jumps, loops and call sequences are
+// followed, but only the data, timing and audio blocks are included; the flow
+// control, metadata, and hardware blocks, along with snapshots, produce no
+// signal. Select blocks offer a choice to the user, so play continues to the
+// next block.
+func (t TZX) BlocksToReach(fileIndex int) ([]Block, error) {
+	files := t.files()
+	if fileIndex < 0 || fileIndex >= len(files) {
+		return nil, fmt.Errorf("file index %d out of range, tape has %d files", fileIndex, len(files))
+	}
+	target := files[fileIndex].DataIndex
+
+	order, err := playbackOrder(t.blocks, 0, t.maxPlaybackSteps())
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Block
+	for _, i := range order {
+		switch t.blocks[i].Category() {
+		case types.CategoryData, types.CategoryTiming, types.CategoryAudio:
+			if t.blocks[i].Id() != types.Snapshot {
+				list = append(list, t.blocks[i])
+			}
+		}
+		if i == target {
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("file '%s' (block #%02d) is not reached during playback", files[fileIndex].Name, target+1)
+}
+
+// ValidatePlayable checks that the tape can be played from start to end,
+// returning the first problem found, or nil if the tape is good:
+//   - groups and loops must be balanced, and groups must not be nested.
+//   - jumps, loops and call sequences must be valid, without infinite loops.
+//   - every data block must be reachable during playback, either directly
+//     or as one of the choices of a Select block.
+//   - standard data blocks, and turbo data blocks loaded by the ROM
+//     routines, must have a valid checksum.
+func (t TZX) ValidatePlayable() error {
+	if err := validateNesting(t.blocks); err != nil {
+		return err
+	}
+
+	// play from the start of the tape, and from each choice of a Select block
+	reachable := make([]bool, len(t.blocks))
+	starts := []int{0}
+	played := map[int]bool{}
+	for len(starts) > 0 {
+		start := starts[0]
+		starts = starts[1:]
+		if played[start] {
+			continue
+		}
+		played[start] = true
+
+		order, err := playbackOrder(t.blocks, start, t.maxPlaybackSteps())
+		if err != nil {
+			return err
+		}
+		for _, i := range order {
+			if !reachable[i] && t.blocks[i].Id() == types.Select {
+				for _, target := range flowTargets(i, t.blocks[i]) {
+					if target < 0 || target >= len(t.blocks) {
+						return fmt.Errorf("block #%02d has a select choice outside the tape", i+1)
+					}
+					starts = append(starts, target)
+				}
+			}
+			reachable[i] = true
+		}
+	}
+
+	for i, block := range t.blocks {
+		if block.Category() == types.CategoryData && !reachable[i] {
+			return fmt.Errorf("data block #%02d is never reached during playback", i+1)
+		}
+		if !dataChecksumValid(block) {
+			return fmt.Errorf("data block #%02d has an invalid checksum", i+1)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks the structure of the tape, returning every problem found:
+//   - groups must be balanced, and must not be nested.
+//   - loops must be balanced.
+//   - the offsets of JumpTo, CallSequence and Select blocks must point to
+//     another block on the tape.
+//
+// Unlike ValidatePlayable, the tape is not played, so infinite loops and
+// unreachable blocks are not detected.
+func (t TZX) Validate() []error {
+	errs := nestingErrors(t.blocks)
+	for i, block := range t.blocks {
+		for _, target := range flowTargets(i, block) {
+			if target == i || target < 0 || target >= len(t.blocks) {
+				errs = append(errs, fmt.Errorf("%s at block #%02d has an invalid offset of %d", block.Name(), i+1, target-i))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateNesting checks that each GroupStart and LoopStart is followed by
+// its matching end block, and that groups are not nested, returning the
+// first problem found.
+func validateNesting(tapeBlocks []Block) error {
+	if errs := nestingErrors(tapeBlocks); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// nestingErrors returns every unbalanced GroupStart/GroupEnd and
+// LoopStart/LoopEnd block, along with any nested groups.
+func nestingErrors(tapeBlocks []Block) []error {
+	var errs []error
+
+	group := -1
+	var loops []int
+
+	for i, block := range tapeBlocks {
+		switch block.Id() {
+		case types.GroupStart:
+			if group >= 0 {
+				errs = append(errs, fmt.Errorf("group at block #%02d is nested inside the group at block #%02d", i+1, group+1))
+			}
+			group = i
+		case types.GroupEnd:
+			if group < 0 {
+				errs = append(errs, fmt.Errorf("group end at block #%02d has no group start", i+1))
+			}
+			group = -1
+		case types.LoopStart:
+			loops = append(loops, i)
+		case types.LoopEnd:
+			if len(loops) == 0 {
+				errs = append(errs, fmt.Errorf("loop end at block #%02d has no loop start", i+1))
+				continue
+			}
+			loops = loops[:len(loops)-1]
+		}
+	}
+
+	if group >= 0 {
+		errs = append(errs, fmt.Errorf("group at block #%02d has no group end", group+1))
+	}
+	for _, loop := range loops {
+		errs = append(errs, fmt.Errorf("loop at block #%02d has no loop end", loop+1))
+	}
+
+	return errs
+}
+
+// dataChecksumValid reports whether a standard data block, or a turbo data
+// block using the ROM timings, has a valid checksum. Other blocks, and
+// fragments too short to hold a checksum, are always reported as valid.
+func dataChecksumValid(block Block) bool {
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		return len(b.Bytes()) < 2 || b.ChecksumValid()
+	case *blocks.TurboSpeedData:
+		return len(b.DataBlock) < 2 || b.LikelyCustomLoader() || b.ChecksumValid()
+	}
+	return true
+}
+
+// playbackOrder returns the indexes of the blocks in the order they are
+// played, starting from the `start` block, and following the JumpTo, loop,
+// and call sequence blocks. The flow control blocks are included in the
+// order. An error is returned if a flow control block points outside the
+// tape, or more than maxSteps blocks are played, which usually means the
+// tape contains an infinite loop.
+func playbackOrder(tapeBlocks []Block, start, maxSteps int) ([]int, error) {
+	var order []int
+
+	type loop struct {
+		start     int // index of the first block in the loop
+		remaining int // number of repetitions left to play
+	}
+	var loops []loop
+
+	callIndex := -1 // index of the CallSequence being played
+	nextCall := 0   // the next call of that sequence to be made
+
+	jump := func(from, offset int) (int, error) {
+		to := from + offset
+		if offset == 0 || to < 0 || to >= len(tapeBlocks) {
+			return 0, fmt.Errorf("block #%02d has an invalid jump offset of %d", from+1, offset)
+		}
+		return to, nil
+	}
+
+	var err error
+	for i := start; i < len(tapeBlocks); {
+		if len(order) >= maxSteps {
+			return nil, fmt.Errorf("playback exceeded %d blocks, the tape may contain an infinite loop", maxSteps)
+		}
+		order = append(order, i)
+
+		switch b := tapeBlocks[i].(type) {
+		case *blocks.JumpTo:
+			if i, err = jump(i, int(b.Value)); err != nil {
+				return nil, err
+			}
+		case *blocks.LoopStart:
+			loops = append(loops, loop{start: i + 1, remaining: int(b.RepetitionCount)})
+			i++
+		case *blocks.LoopEnd:
+			i++
+			if len(loops) > 0 {
+				loops[len(loops)-1].remaining--
+				if loops[len(loops)-1].remaining > 0 {
+					i = loops[len(loops)-1].start
+				} else {
+					loops = loops[:len(loops)-1]
+				}
+			}
+		case *blocks.CallSequence:
+			if callIndex >= 0 {
+				return nil, fmt.Errorf("block #%02d is a call sequence nested inside the call of block #%02d", i+1, callIndex+1)
+			}
+			if len(b.Blocks) == 0 {
+				i++
+				break
+			}
+			callIndex, nextCall = i, 1
+			if i, err = jump(i, int(int16(b.Blocks[0]))); err != nil {
+				return nil, err
+			}
+		case *blocks.ReturnFromSequence:
+			if callIndex < 0 {
+				i++ // not in a call, so the block is ignored
+				break
+			}
+			calls := tapeBlocks[callIndex].(*blocks.CallSequence).Blocks
+			if nextCall < len(calls) {
+				if i, err = jump(callIndex, int(int16(calls[nextCall]))); err != nil {
+					return nil, err
+				}
+				nextCall++
+			} else {
+				i = callIndex + 1
+				callIndex = -1
+			}
+		default:
+			i++
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,103 @@
+package tzx
+
+import (
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// standardTAPBlock returns a Standard Speed Data block, with a pause of
+// 1000ms, for the TAP block with the flag and data, adding the checksum.
+func standardTAPBlock(flag uint8, data []byte) []byte {
+	checksum := flag
+	for _, b := range data {
+		checksum ^= b
+	}
+	length := len(data) + 2
+
+	raw := []byte{0x10, 0xe8, 0x03, byte(length), byte(length >> 8), flag}
+	raw = append(raw, data...)
+	return append(raw, checksum)
+}
+
+// codeHeader returns a standard header block for a CODE file of the given length.
+func codeHeader(name string, length uint16) []byte {
+	data := []byte{0x03}
+	data = append(data, []byte(name + "          ")[:10]...)
+	data = append(data, byte(length), byte(length>>8), 0x00, 0x80, 0x00, 0x80)
+	return standardTAPBlock(0x00, data)
+}
+
+func TestBlocksToReach(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x33, 0x01, 0x00, 0x00, 0x00}, // hardware type
+		codeHeader("level1", 2),
+		standardTAPBlock(0xff, []byte{1, 1}),
+		[]byte{0x30, 0x05, 'S', 't', 'o', 'p', '!'}, // text description
+		[]byte{0x20, 0x00, 0x00},                    // stop the tape
+		codeHeader("level2", 2),
+		standardTAPBlock(0xff, []byte{2, 2}),
+		[]byte{0x20, 0x00, 0x00}, // stop the tape
+		codeHeader("level3", 2),
+		standardTAPBlock(0xff, []byte{3, 3}),
+	)
+	tape := readTape(t, raw)
+
+	list, err := tape.BlocksToReach(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []types.BlockType{
+		types.StandardSpeedData, types.StandardSpeedData,
+		types.PauseTapeCommand,
+		types.StandardSpeedData, types.StandardSpeedData,
+	}
+	if len(list) != len(want) {
+		t.Fatalf("expected %d blocks, got %d", len(want), len(list))
+	}
+	for i, block := range list {
+		if block.Id() != want[i] {
+			t.Errorf("block %d: expected ID 0x%02X, got 0x%02X", i, uint8(want[i]), uint8(block.Id()))
+		}
+	}
+	if list[4] != tape.Blocks()[6] {
+		t.Error("expected the last block to be the level 2 data block")
+	}
+
+	if _, err := tape.BlocksToReach(3); err == nil {
+		t.Error("expected an error for a file index out of range")
+	}
+}
+
+func TestValidatePlayable(t *testing.T) {
+	t.Run("good tape", func(t *testing.T) {
+		tape := readTape(t, tzxFile(20,
+			codeHeader("level1", 2),
+			standardTAPBlock(0xff, []byte{1, 1}),
+			[]byte{0x24, 0x02, 0x00}, // loop start, 2 repetitions
+			[]byte{0x12, 0x78, 0x08, 0x10, 0x00},
+			[]byte{0x25}, // loop end
+			codeHeader("level2", 2),
+			standardTAPBlock(0xff, []byte{2, 2}),
+		))
+
+		if err := tape.ValidatePlayable(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unreachable block", func(t *testing.T) {
+		tape := readTape(t, tzxFile(20,
+			codeHeader("level1", 2),
+			standardTAPBlock(0xff, []byte{1, 1}),
+			[]byte{0x23, 0x02, 0x00}, // jump over the next block
+			standardTAPBlock(0xff, []byte{2, 2}),
+			[]byte{0x20, 0xe8, 0x03},
+		))
+
+		if err := tape.ValidatePlayable(); err == nil {
+			t.Error("expected an error for the unreachable data block")
+		}
+	})
+}
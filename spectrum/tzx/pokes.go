@@ -0,0 +1,23 @@
+package tzx
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Pokes returns the trainers from all POKEs custom info blocks on the tape.
+// Any block with malformed POKEs data is skipped.
+func (t TZX) Pokes() []blocks.PokeGroup {
+	var trainers []blocks.PokeGroup
+
+	for _, block := range t.blocks {
+		info, ok := block.(*blocks.CustomInfo)
+		if !ok || !info.IsPokes() {
+			continue
+		}
+		if _, pokes, err := info.Pokes(); err == nil {
+			trainers = append(trainers, pokes...)
+		}
+	}
+
+	return trainers
+}
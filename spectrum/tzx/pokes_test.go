@@ -0,0 +1,66 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// customInfo returns a Custom Info block with the identification and info bytes.
+func customInfo(id string, info []byte) []byte {
+	raw := append([]byte{0x35}, []byte(id + "                ")[:16]...)
+	length := len(info)
+	raw = append(raw, byte(length), byte(length>>8), byte(length>>16), byte(length>>24))
+	return append(raw, info...)
+}
+
+func TestPokes(t *testing.T) {
+	lives := []byte{
+		0x00,                          // no general description
+		0x01,                          // one trainer
+		0x05, 'L', 'i', 'v', 'e', 's', // trainer description
+		0x01,                         // one POKE
+		0x08, 0x00, 0x80, 0x00, 0x03, // 48K page, 32768, 0, originally 3
+	}
+	timer := []byte{
+		0x01, 'x',
+		0x01,
+		0x04, 'T', 'i', 'm', 'e',
+		0x02,
+		0x03, 0x00, 0xc0, 0xff, 0x10, // page 3, 49152, 255, originally 16
+		0x30, 0x34, 0x12, 0x00, 0x00, // ask for a value, ignore the original
+	}
+	malformed := []byte{0x00, 0x01, 0x05, 'B', 'r', 'o', 'k', 'e', 'n', 0x01, 0x08}
+
+	raw := tzxFile(20,
+		customInfo("POKEs", lives),
+		standardBlock,
+		customInfo("Instructions", []byte("Press any key")),
+		customInfo("POKEs", malformed),
+		customInfo("POKEs", timer),
+	)
+
+	want := []blocks.PokeGroup{
+		{Description: "Lives", Pokes: []blocks.Poke{{Type: 0x08, Address: 0x8000, Value: 0x00, Original: 0x03}}},
+		{Description: "Time", Pokes: []blocks.Poke{
+			{Type: 0x03, Address: 0xc000, Value: 0xff, Original: 0x10},
+			{Type: 0x30, Address: 0x1234, Value: 0x00, Original: 0x00},
+		}},
+	}
+
+	got := readTape(t, raw).Pokes()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected trainers %+v, got %+v", want, got)
+	}
+
+	if poke := got[0].Pokes[0]; !poke.IgnorePage() || poke.AskForValue() {
+		t.Errorf("expected the Lives POKE to ignore the page, and not ask for a value")
+	}
+	if poke := got[1].Pokes[0]; poke.Page() != 3 || poke.IgnorePage() {
+		t.Errorf("expected the first Time POKE to use page 3, got %d", poke.Page())
+	}
+	if poke := got[1].Pokes[1]; !poke.AskForValue() || !poke.IgnoreOriginal() {
+		t.Errorf("expected the second Time POKE to ask for a value, and ignore the original")
+	}
+}
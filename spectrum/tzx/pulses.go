@@ -0,0 +1,97 @@
+package tzx
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// PulseCounts returns the number of pulses generated by each block on the
+// tape, including any ArchiveInfo block, as played by StreamPulses. The
+// blocks are played in the order given by LinearBlocks, so a block played
+// more than once, by a loop or call sequence, is counted for each play, and
+// the pulses of a pause are counted for the block it belongs to. Blocks that
+// generate no pulses, such as text and flow control blocks, have a count of 0.
+//
+// If the flow control blocks are invalid then each block is played once, in
+// file order, and a block that can not be played is only counted up to the
+// pulse where playback failed.
+func (t TZX) PulseCounts() []int {
+	order, err := playbackOrder(t.blocks, 0, t.maxPlaybackSteps())
+	if err != nil {
+		order = make([]int, len(t.blocks))
+		for i := range order {
+			order[i] = i
+		}
+	}
+
+	counts := make([]int, len(t.blocks))
+	current := 0
+	p := &pulseStream{emit: func(Pulse) error {
+		counts[current]++
+		return nil
+	}}
+	for _, i := range order {
+		current = i
+		_ = p.block(t.blocks[i])
+	}
+
+	return counts
+}
+
+// TotalPulseCount returns the number of pulses generated by the whole tape,
+// which is the sum of the PulseCounts.
+func (t TZX) TotalPulseCount() int {
+	total := 0
+	for _, count := range t.PulseCounts() {
+		total += count
+	}
+	return total
+}
+
+// blockPulseCount returns the number of pulses generated by a single play of the block.
+func blockPulseCount(block Block) int {
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		return b.PilotPulseCount() + 2 + 2*dataBitCount(b.Bytes(), 8)
+	case *blocks.TurboSpeedData:
+		return int(b.PilotTone) + 2 + 2*dataBitCount(b.DataBlock, b.UsedBits)
+	case *blocks.PureTone:
+		return int(b.PulseCount)
+	case *blocks.SequenceOfPulses:
+		return len(b.Lengths)
+	case *blocks.PureData:
+		return 2 * dataBitCount(b.DataBlock, b.UsedBits)
+	case *blocks.DirectRecording:
+		// each run of samples at the same level is a single pulse
+		count := 0
+		samples := b.Samples()
+		for i := range samples {
+			if i == 0 || samples[i] != samples[i-1] {
+				count++
+			}
+		}
+		return count
+	case *blocks.CswRecording:
+		// the stored pulse count is only for validation, so may be wrong
+		pulses, err := b.Pulses()
+		if err != nil {
+			return 0
+		}
+		return len(pulses)
+	case *blocks.GeneralizedData:
+		count, _, _ := generalizedDataPulses(b, false)
+		return count
+	}
+	return 0
+}
+
+// dataBitCount returns the number of bits played for the data, where only
+// the used bits of the last byte are played.
+func dataBitCount(data []byte, usedBits uint8) int {
+	if len(data) == 0 {
+		return 0
+	}
+	if usedBits == 0 || usedBits > 8 {
+		usedBits = 8
+	}
+	return (len(data)-1)*8 + int(usedBits)
+}
@@ -0,0 +1,53 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+)
+
+// pureTone returns a Pure Tone block of count pulses, each of the given length.
+func pureTone(length, count uint16) []byte {
+	return []byte{0x12, byte(length), byte(length >> 8), byte(count), byte(count >> 8)}
+}
+
+func TestPulseCountsPureTone(t *testing.T) {
+	counts := readTape(t, tzxFile(20, pureTone(2168, 3223))).PulseCounts()
+	if !reflect.DeepEqual(counts, []int{3223}) {
+		t.Errorf("expected the pulse count to be the tone's pulse count, got %v", counts)
+	}
+}
+
+func TestPulseCountsMatchesAllPulses(t *testing.T) {
+	raw := tzxFile(20,
+		pureTone(2168, 3),                    // 0
+		[]byte{0x24, 0x02, 0x00},             // 1: loop start, 2 repetitions
+		pureTone(1000, 5),                    // 2
+		[]byte{0x25},                         // 3: loop end
+		[]byte{0x23, 0x02, 0x00},             // 4: jump to 6
+		pureTone(500, 7),                     // 5: skipped
+		[]byte{0x26, 0x01, 0x00, 0x02, 0x00}, // 6: call 8
+		[]byte{0x23, 0x03, 0x00},             // 7: jump to 10
+		pureTone(800, 1),                     // 8
+		[]byte{0x27},                         // 9: return
+		[]byte{ // 10: CSW recording of 3 RLE pulses, with a stored pulse count of 9
+			0x18, 0x0d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x44, 0xac, 0x00, 0x01, 0x09, 0x00, 0x00, 0x00,
+			10, 20, 30,
+		},
+		[]byte{0x20, 0x64, 0x00}, // 11: 100ms pause
+	)
+	tape := readTape(t, raw)
+
+	counts := tape.PulseCounts()
+	want := []int{3, 0, 10, 0, 0, 0, 0, 0, 1, 0, 3, 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("expected pulse counts %v, got %v", want, counts)
+	}
+
+	pulses, err := tape.AllPulses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total := tape.TotalPulseCount(); total != len(pulses) {
+		t.Errorf("expected a total of %d pulses, as played, got %d", len(pulses), total)
+	}
+}
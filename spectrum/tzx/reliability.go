@@ -0,0 +1,117 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Thresholds used when checking the loading reliability of a tape.
+const (
+	minimumBlockPause     = 100   // ms. between data blocks, to give a loader time to process the data
+	minimumPilotTone      = 1000  // pulses needed for a loader to reliably sync to the tone
+	maximumPilotTone      = 16126 // pulses, twice that of a standard header
+	borderlineTimingLower = 5     // percentage difference from the ROM timings
+	borderlineTimingUpper = 15    // percentage difference from the ROM timings
+)
+
+// ReliabilityReport checks the tape for features that may cause loading
+// problems on real hardware, such as very short pauses between data blocks,
+// timings that are close to, but not quite, the ROM values, and pilot tones
+// that are missing, too short or overly long. Each finding names the block
+// number (starting from 1) and the concern.
+func (t TZX) ReliabilityReport() []string {
+	var findings []string
+
+	for i, block := range t.blocks {
+		number := i + 1
+
+		// pause between this block and a following data block
+		if pause, ok := dataBlockPause(block); ok && pause < minimumBlockPause && nextIsDataBlock(t.blocks, i) {
+			findings = append(findings, fmt.Sprintf("block #%02d: short pause of %d ms before the next data block", number, pause))
+		}
+
+		switch b := block.(type) {
+		case *blocks.TurboSpeedData:
+			if b.PilotTone < minimumPilotTone {
+				findings = append(findings, fmt.Sprintf("block #%02d: short pilot tone of %d pulses", number, b.PilotTone))
+			} else if b.PilotTone > maximumPilotTone {
+				findings = append(findings, fmt.Sprintf("block #%02d: overly long pilot tone of %d pulses", number, b.PilotTone))
+			}
+
+			timings := []struct {
+				name            string
+				value, standard uint16
+			}{
+				{"pilot pulse", b.PilotPulse, blocks.StandardPilotPulse},
+				{"first sync pulse", b.SyncFirstPulse, blocks.StandardSyncFirstPulse},
+				{"second sync pulse", b.SyncSecondPulse, blocks.StandardSyncSecondPulse},
+				{"zero bit pulse", b.ZeroBitPulse, blocks.StandardZeroBitPulse},
+				{"one bit pulse", b.OneBitPulse, blocks.StandardOneBitPulse},
+			}
+			for _, timing := range timings {
+				if isBorderline(timing.value, timing.standard) {
+					findings = append(findings, fmt.Sprintf(
+						"block #%02d: borderline %s timing of %d T-states (ROM: %d)",
+						number, timing.name, timing.value, timing.standard,
+					))
+				}
+			}
+		case *blocks.PureData:
+			if i == 0 || !isToneBlock(t.blocks[i-1]) {
+				findings = append(findings, fmt.Sprintf("block #%02d: data has no lead-in tone", number))
+			}
+		case *blocks.PureTone:
+			if b.PulseCount > maximumPilotTone {
+				findings = append(findings, fmt.Sprintf("block #%02d: overly long tone of %d pulses", number, b.PulseCount))
+			}
+		}
+	}
+
+	return findings
+}
+
+// dataBlockPause returns the pause embedded in a data block.
+func dataBlockPause(block Block) (uint16, bool) {
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		return b.Pause, true
+	case *blocks.TurboSpeedData:
+		return b.Pause, true
+	case *blocks.PureData:
+		return b.Pause, true
+	}
+	return 0, false
+}
+
+// nextIsDataBlock reports whether the block following the index starts a new
+// data sequence, i.e. it is a data block with its own pilot tone, or a tone.
+func nextIsDataBlock(tapeBlocks []Block, index int) bool {
+	if index+1 >= len(tapeBlocks) {
+		return false
+	}
+	switch tapeBlocks[index+1].(type) {
+	case *blocks.StandardSpeedData, *blocks.TurboSpeedData, *blocks.PureTone:
+		return true
+	}
+	return false
+}
+
+// isToneBlock reports whether the block produces a pilot or sync tone.
+func isToneBlock(block Block) bool {
+	switch block.(type) {
+	case *blocks.PureTone, *blocks.SequenceOfPulses:
+		return true
+	}
+	return false
+}
+
+// isBorderline reports whether the value differs from the standard value by
+// more than a small amount, but not enough to be a deliberate custom timing.
+func isBorderline(value, standard uint16) bool {
+	diff := int(value) - int(standard)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*100 > int(standard)*borderlineTimingLower && diff*100 <= int(standard)*borderlineTimingUpper
+}
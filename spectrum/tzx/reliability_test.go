@@ -0,0 +1,37 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReliabilityReport(t *testing.T) {
+	shortPause := append([]byte{}, standardBlock...)
+	shortPause[1], shortPause[2] = 50, 0
+
+	turbo := turboBlock(0xff, []byte{1, 2, 3}, 0xff)
+	turbo[7], turbo[8] = 0xac, 0x03   // zero bit pulse of 940 T-states, 10% over the ROM timing
+	turbo[11], turbo[12] = 0xf4, 0x01 // pilot tone of 500 pulses
+
+	pureData := []byte{0x14, 0x57, 0x03, 0xae, 0x06, 0x08, 0xe8, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff}
+
+	raw := tzxFile(20, shortPause, standardBlock, turbo, pureData, pureTone(2168, 3223), pureData)
+	findings := readTape(t, raw).ReliabilityReport()
+
+	want := []string{
+		"block #01: short pause of 50 ms before the next data block",
+		"block #03: short pilot tone of 500 pulses",
+		"block #03: borderline zero bit pulse timing of 940 T-states (ROM: 855)",
+		"block #04: data has no lead-in tone",
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("unexpected findings\nexpected: %q\ngot:      %q", want, findings)
+	}
+}
+
+func TestReliabilityReportStandardTape(t *testing.T) {
+	findings := readTape(t, tzxFile(20, standardBlock, standardBlock)).ReliabilityReport()
+	if len(findings) != 0 {
+		t.Errorf("expected no findings for a standard tape, got %q", findings)
+	}
+}
@@ -0,0 +1,65 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Segment is a range of blocks from one of the TZX files that were joined to
+// make the tape, where each appended file starts with a GlueBlock.
+type Segment struct {
+	Start        int                 // Index of the first block (starting from 0)
+	End          int                 // Index after the last block
+	MajorVersion uint8               // TZX major revision of the file
+	MinorVersion uint8               // TZX minor revision of the file
+	ArchiveInfo  *blocks.ArchiveInfo // First ArchiveInfo block of the file, or nil
+}
+
+// Segments returns the blocks of each of the TZX files joined to make the
+// tape. The first segment uses the version from the tape header, and each
+// following segment starts with its GlueBlock, using the version given
+// there. Each segment is given the ArchiveInfo found within its own blocks.
+// A tape without any GlueBlocks is returned as a single segment.
+func (t TZX) Segments() []Segment {
+	segments := []Segment{{MajorVersion: t.MajorVersion, MinorVersion: t.MinorVersion}}
+	for i, block := range t.blocks {
+		switch b := block.(type) {
+		case *blocks.GlueBlock:
+			segments[len(segments)-1].End = i
+
+			major, minor := b.Version()
+			segments = append(segments, Segment{Start: i, MajorVersion: major, MinorVersion: minor})
+		case *blocks.ArchiveInfo:
+			if segments[len(segments)-1].ArchiveInfo == nil {
+				segments[len(segments)-1].ArchiveInfo = b
+			}
+		}
+	}
+	segments[len(segments)-1].End = len(t.blocks)
+
+	return segments
+}
+
+// Split returns each of the TZX files joined to make the tape (see Segments)
+// as a tape of its own, without the GlueBlock, and using the version of the
+// original file. The ArchiveInfo of each tape is therefore that of its file.
+// An error is returned if a flow control block refers to another file.
+func (t *TZX) Split() ([]*TZX, error) {
+	var tapes []*TZX
+	for i, segment := range t.Segments() {
+		start := segment.Start
+		if i > 0 {
+			start++ // skip the glue block
+		}
+
+		tape, err := t.Slice(start, segment.End)
+		if err != nil {
+			return nil, fmt.Errorf("unable to split file #%d from the tape: %v", i+1, err)
+		}
+		tape.MajorVersion, tape.MinorVersion = segment.MajorVersion, segment.MinorVersion
+
+		tapes = append(tapes, tape)
+	}
+	return tapes, nil
+}
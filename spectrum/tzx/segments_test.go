@@ -0,0 +1,80 @@
+package tzx
+
+import (
+	"testing"
+)
+
+// archiveTitle returns an ArchiveInfo block holding only the title.
+func archiveTitle(title string) []byte {
+	length := 3 + len(title)
+	raw := []byte{0x32, byte(length), byte(length >> 8), 0x01, 0x00, byte(len(title))}
+	return append(raw, title...)
+}
+
+// glueBlock returns a GlueBlock for a joined file of the given v1.x revision.
+func glueBlock(minorVersion uint8) []byte {
+	return []byte{0x5a, 'X', 'T', 'a', 'p', 'e', '!', 0x1a, 1, minorVersion}
+}
+
+func TestSegmentsArchiveInfo(t *testing.T) {
+	raw := tzxFile(20,
+		archiveTitle("Side A"), standardBlock,
+		glueBlock(13), archiveTitle("Side B"), standardBlock, standardBlock,
+	)
+	tape := readTape(t, raw)
+
+	segments := tape.Segments()
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	for i, want := range []Segment{{Start: 0, End: 2}, {Start: 2, End: 6}} {
+		if segments[i].Start != want.Start || segments[i].End != want.End {
+			t.Errorf("segment %d: expected blocks [%d:%d], got [%d:%d]", i, want.Start, want.End, segments[i].Start, segments[i].End)
+		}
+	}
+
+	for i, title := range []string{"Side A", "Side B"} {
+		if segments[i].ArchiveInfo == nil {
+			t.Errorf("segment %d: expected an ArchiveInfo", i)
+		} else if segments[i].ArchiveInfo.Title() != title {
+			t.Errorf("segment %d: expected the title %q, got %q", i, title, segments[i].ArchiveInfo.Title())
+		}
+	}
+
+	tapes, err := tape.Split()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tapes) != 2 {
+		t.Fatalf("expected 2 tapes, got %d", len(tapes))
+	}
+
+	for i, want := range []struct {
+		title  string
+		minor  uint8
+		blocks int
+	}{{"Side A", 20, 2}, {"Side B", 13, 3}} {
+		archive, ok := tapes[i].ArchiveInfo()
+		if !ok || archive.Title() != want.title {
+			t.Errorf("tape %d: expected the ArchiveInfo title %q", i, want.title)
+		}
+		if tapes[i].MinorVersion != want.minor {
+			t.Errorf("tape %d: expected v1.%02d, got v1.%02d", i, want.minor, tapes[i].MinorVersion)
+		}
+		if len(tapes[i].Blocks()) != want.blocks {
+			t.Errorf("tape %d: expected %d blocks, got %d", i, want.blocks, len(tapes[i].Blocks()))
+		}
+	}
+}
+
+func TestSegmentsWithoutArchiveInfo(t *testing.T) {
+	segments := readTape(t, tzxFile(20, standardBlock, glueBlock(20), standardBlock)).Segments()
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(segments))
+	}
+	for i, segment := range segments {
+		if segment.ArchiveInfo != nil {
+			t.Errorf("segment %d: expected no ArchiveInfo, got %q", i, segment.ArchiveInfo.Title())
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Pulse is a single level of the tape signal, lasting for the given
+// number of T-states.
+type Pulse struct {
+	Level   bool   // true for a high level, false for low
+	TStates uint32 // Length of the pulse in T-states
+}
+
+// StreamPulses plays the tape, calling fn with each pulse of the signal.
+// Playback starts at a low level, and follows the TZX rules for the
+// 'current pulse level' (see PulseLevels). The flow control blocks are
+// followed, playing the blocks in the order given by LinearBlocks.
+// A non-zero pause is played as 1ms at the current level, to finish the
+// last edge, followed by the remainder at a low level. A Pause block of 0
+// stops the tape, which finishes the last edge and leaves the level low,
+// without any further silence.
+//
+// If fn returns an error then playback stops and the error is returned.
+func (t TZX) StreamPulses(fn func(Pulse) error) error {
+	linear, err := t.LinearBlocks()
+	if err != nil {
+		return err
+	}
+
+	p := &pulseStream{emit: fn}
+	for _, block := range linear {
+		if err := p.block(block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AllPulses returns every pulse of the tape, as played by StreamPulses.
+//
+// Each pulse uses 8 bytes of memory, and a CSW or Direct Recording block
+// of a few minutes can easily produce several million pulses. For large
+// tapes, StreamPulses should be used to process the pulses as they are
+// generated.
+func (t TZX) AllPulses() ([]Pulse, error) {
+	var pulses []Pulse
+
+	err := t.StreamPulses(func(p Pulse) error {
+		pulses = append(pulses, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pulses, nil
+}
+
+// pulseStream tracks the current pulse level while emitting the pulses.
+type pulseStream struct {
+	PulseState
+	emit func(Pulse) error
+}
+
+// pulse emits a pulse at the current level, then toggles the level so that
+// the next pulse produces an edge.
+func (p *pulseStream) pulse(tStates uint32) error {
+	if err := p.emit(Pulse{Level: p.Level, TStates: tStates}); err != nil {
+		return err
+	}
+	p.Level = !p.Level
+	return nil
+}
+
+// tone emits `count` pulses of the same length.
+func (p *pulseStream) tone(tStates uint32, count int) error {
+	for i := 0; i < count; i++ {
+		if err := p.pulse(tStates); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pulses emits each of the pulse lengths in turn.
+func (p *pulseStream) pulses(lengths []uint16) error {
+	for _, length := range lengths {
+		if err := p.pulse(uint32(length)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// data emits two pulses for each bit, MSb first, using only the used bits
+// of the last byte.
+func (p *pulseStream) data(data []byte, usedBits uint8, zeroBitPulse, oneBitPulse uint16) error {
+	for i := 0; i < dataBitCount(data, usedBits); i++ {
+		length := uint32(zeroBitPulse)
+		if data[i/8]&(0x80>>uint(i%8)) != 0 {
+			length = uint32(oneBitPulse)
+		}
+		if err := p.tone(length, 2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pause emits the pause, leaving the level low. A zero pause is ignored.
+func (p *pulseStream) pause(ms uint16) error {
+	if ms == 0 {
+		return nil
+	}
+
+	tStates := uint32(ms) * blocks.TStatesPerSecond / 1000
+	if p.Level {
+		if err := p.finishEdge(); err != nil {
+			return err
+		}
+		tStates -= blocks.TStatesPerSecond / 1000
+	}
+
+	if tStates == 0 {
+		return nil
+	}
+	return p.emit(Pulse{Level: false, TStates: tStates})
+}
+
+// finishEdge finishes the last edge with 1ms at the opposite (high) level,
+// then sets the level low.
+func (p *pulseStream) finishEdge() error {
+	if err := p.emit(Pulse{Level: true, TStates: blocks.TStatesPerSecond / 1000}); err != nil {
+		return err
+	}
+	p.Level = false
+	return nil
+}
+
+// stopTape finishes any last edge, leaving the level low, as when a player
+// stops the tape.
+func (p *pulseStream) stopTape() error {
+	if !p.Level {
+		return nil
+	}
+	return p.finishEdge()
+}
+
+// block emits the pulses for a single play of the block, including its pause.
+func (p *pulseStream) block(block Block) error {
+	var err error
+
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		err = p.pulses(b.Pulses())
+	case *blocks.TurboSpeedData:
+		err = p.tone(uint32(b.PilotPulse), int(b.PilotTone))
+		if err == nil {
+			err = p.tone(uint32(b.SyncFirstPulse), 1)
+		}
+		if err == nil {
+			err = p.tone(uint32(b.SyncSecondPulse), 1)
+		}
+		if err == nil {
+			err = p.data(b.DataBlock, b.UsedBits, b.ZeroBitPulse, b.OneBitPulse)
+		}
+	case *blocks.PureTone:
+		err = p.tone(uint32(b.Length), int(b.PulseCount))
+	case *blocks.SequenceOfPulses:
+		err = p.pulses(b.Lengths)
+	case *blocks.PureData:
+		err = p.data(b.DataBlock, b.UsedBits, b.ZeroBitPulse, b.OneBitPulse)
+	case *blocks.DirectRecording:
+		err = p.directRecording(b)
+	case *blocks.CswRecording:
+		err = p.cswRecording(b)
+	case *blocks.GeneralizedData:
+		err = p.generalizedData(b)
+	case *blocks.SetSignalLevel:
+		p.Level = b.SignalLevel == 1
+	case *blocks.PauseTapeCommand:
+		if b.IsStopTheTape() {
+			err = p.stopTape()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.pause(block.PauseMs())
+}
+
+// directRecording emits each run of samples at the same level as a single
+// pulse. The level is left at the last level played.
+func (p *pulseStream) directRecording(b *blocks.DirectRecording) error {
+	samples := b.Samples()
+
+	for i := 0; i < len(samples); {
+		run := 1
+		for i+run < len(samples) && samples[i+run] == samples[i] {
+			run++
+		}
+		if err := p.emit(Pulse{Level: samples[i], TStates: uint32(run) * uint32(b.TStatesPerSample)}); err != nil {
+			return err
+		}
+		p.Level = samples[i]
+		i += run
+	}
+
+	return nil
+}
+
+// cswRecording emits the decompressed pulses, converting their lengths from
+// samples to T-states. The level is left at the last level played.
+func (p *pulseStream) cswRecording(b *blocks.CswRecording) error {
+	if b.SampleRate == 0 {
+		return fmt.Errorf("CSW recording has a sample rate of 0")
+	}
+
+	pulses, err := b.Pulses()
+	if err != nil {
+		return err
+	}
+
+	// positions are tracked in samples so rounding errors do not accumulate
+	var position, tStates uint64
+	for _, samples := range pulses {
+		position += uint64(samples)
+		end := position * blocks.TStatesPerSecond / uint64(b.SampleRate)
+		if err := p.pulse(uint32(end - tStates)); err != nil {
+			return err
+		}
+		tStates = end
+	}
+	if len(pulses) > 0 {
+		p.Level = !p.Level
+	}
+
+	return nil
+}
+
+// generalizedData emits the pilot/sync symbols, repeating each as given by
+// the RLE stream, followed by the symbols of the data stream.
+func (p *pulseStream) generalizedData(b *blocks.GeneralizedData) error {
+	for _, rle := range b.PilotStreams {
+		if int(rle.Symbol) >= len(b.PilotSymbols) {
+			return fmt.Errorf("pilot/sync symbol %d is not in the alphabet of %d symbols", rle.Symbol, len(b.PilotSymbols))
+		}
+		for i := 0; i < int(rle.RepetitionCount); i++ {
+			if err := p.symbol(b.PilotSymbols[rle.Symbol]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if b.TOTD == 0 {
+		return nil
+	}
+	if need := (uint64(b.SymbolBits())*uint64(b.TOTD) + 7) / 8; uint64(len(b.DataStreams)) < need {
+		return fmt.Errorf("data stream has %d bytes, expected %d", len(b.DataStreams), need)
+	}
+	for i := 0; i < int(b.TOTD); i++ {
+		symbol := b.DataSymbol(i)
+		if symbol >= len(b.DataSymbols) {
+			return fmt.Errorf("data symbol %d is not in the alphabet of %d symbols", symbol, len(b.DataSymbols))
+		}
+		if err := p.symbol(b.DataSymbols[symbol]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generalizedDataPulses plays the block from the given level, as done by
+// StreamPulses but without emitting the signal, returning the number of
+// pulses, their total length in T-states, and the level after the last pulse.
+// Play stops at an invalid symbol, so only the pulses before it are counted.
+func generalizedDataPulses(b *blocks.GeneralizedData, level bool) (count int, tStates uint64, endLevel bool) {
+	p := &pulseStream{PulseState: PulseState{Level: level}}
+	p.emit = func(pulse Pulse) error {
+		count++
+		tStates += uint64(pulse.TStates)
+		return nil
+	}
+	_ = p.generalizedData(b)
+
+	return count, tStates, p.Level
+}
+
+// symbol emits the pulses of a generalized data symbol, after setting the
+// level given by the polarity flags of the symbol. A symbol without any
+// pulses is ignored.
+func (p *pulseStream) symbol(s blocks.Symbol) error {
+	pulses := s.Pulses()
+	if len(pulses) == 0 {
+		return nil
+	}
+
+	switch s.Flags & 0x03 {
+	case 0x01: // no edge, so the previous pulse is prolonged
+		p.Level = !p.Level
+	case 0x02:
+		p.Level = false
+	case 0x03:
+		p.Level = true
+	}
+
+	return p.pulses(pulses)
+}
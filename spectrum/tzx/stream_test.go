@@ -0,0 +1,133 @@
+package tzx
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+func TestIsStopTheTape(t *testing.T) {
+	if !blocks.NewPause(0).IsStopTheTape() {
+		t.Error("expected a pause of 0 to stop the tape")
+	}
+	if blocks.NewPause(100).IsStopTheTape() {
+		t.Error("expected a pause of 100ms not to stop the tape")
+	}
+}
+
+func TestAllPulsesPureTone(t *testing.T) {
+	pulses, err := readTape(t, tzxFile(20, pureTone(2168, 4))).AllPulses()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Pulse{{false, 2168}, {true, 2168}, {false, 2168}, {true, 2168}}
+	if !reflect.DeepEqual(pulses, want) {
+		t.Errorf("expected pulses %v, got %v", want, pulses)
+	}
+}
+
+func TestStreamPausesAndStops(t *testing.T) {
+	oneTone := []byte{0x12, 0x78, 0x08, 0x01, 0x00}  // a single pulse of 2168 T-states
+	twoTones := []byte{0x12, 0x78, 0x08, 0x02, 0x00} // two pulses of 2168 T-states
+	pause := []byte{0x20, 0x64, 0x00}                // 100ms
+	stop := []byte{0x20, 0x00, 0x00}
+
+	const ms = blocks.TStatesPerSecond / 1000
+
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		want   []Pulse
+	}{
+		{
+			"stop after a high level finishes the edge, without silence",
+			[][]byte{oneTone, stop},
+			[]Pulse{{false, 2168}, {true, ms}},
+		},
+		{
+			"stop after a low level emits nothing",
+			[][]byte{twoTones, stop},
+			[]Pulse{{false, 2168}, {true, 2168}},
+		},
+		{
+			"pause after a high level finishes the edge, then plays low",
+			[][]byte{oneTone, pause},
+			[]Pulse{{false, 2168}, {true, ms}, {false, 99 * ms}},
+		},
+		{
+			"pause after a low level plays low",
+			[][]byte{twoTones, pause},
+			[]Pulse{{false, 2168}, {true, 2168}, {false, 100 * ms}},
+		},
+		{
+			"stop leaves the level low for the next block",
+			[][]byte{oneTone, stop, oneTone},
+			[]Pulse{{false, 2168}, {true, ms}, {false, 2168}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pulses, err := readTape(t, tzxFile(20, tt.blocks...)).AllPulses()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(pulses, tt.want) {
+				t.Errorf("expected pulses %v, got %v", tt.want, pulses)
+			}
+		})
+	}
+}
+
+func TestStreamUsedBits(t *testing.T) {
+	const zero, one = 855, 1710
+	data := []byte{0x00, 0xa5} // the last byte is 10100101
+
+	for usedBits := uint8(1); usedBits <= 7; usedBits++ {
+		var want []uint32
+		for i := 0; i < 8; i++ {
+			want = append(want, zero, zero)
+		}
+		for i := uint8(0); i < usedBits; i++ {
+			length := uint32(zero)
+			if data[1]&(0x80>>i) != 0 {
+				length = one
+			}
+			want = append(want, length, length)
+		}
+
+		tests := []struct {
+			block Block
+			lead  []uint32 // pilot and sync pulses before the data
+		}{
+			{&blocks.PureData{ZeroBitPulse: zero, OneBitPulse: one, UsedBits: usedBits, DataBlock: data}, nil},
+			{&blocks.TurboSpeedData{
+				SyncFirstPulse: 667, SyncSecondPulse: 735,
+				ZeroBitPulse: zero, OneBitPulse: one, UsedBits: usedBits, DataBlock: data,
+			}, []uint32{667, 735}},
+		}
+		for _, tt := range tests {
+			block := tt.block
+			want := append(append([]uint32(nil), tt.lead...), want...)
+
+			var got []uint32
+			p := &pulseStream{emit: func(pulse Pulse) error {
+				got = append(got, pulse.TStates)
+				return nil
+			}}
+			if err := p.block(block); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if count := blockPulseCount(block); count != len(want) {
+				t.Errorf("%s with %d used bits: expected a pulse count of %d, got %d", block.Name(), usedBits, len(want), count)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s with %d used bits: expected pulses %v, got %v", block.Name(), usedBits, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package tzx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+	"github.com/mrcook/retroio/storage"
+)
+
+// tapPauseMs is the pause used after each block of an imported TAP file,
+// which is the standard pause of the ROM saving routines.
+const tapPauseMs = 1000
+
+// ReadTAP reads a TAP file, returning a tape with each TAP block wrapped in a
+// StandardSpeedData block, using the ROM timings and a pause of 1000 ms.
+// The tape can then be used as if it had been read from a TZX file.
+func ReadTAP(r io.Reader) (*TZX, error) {
+	tapFile := tap.New(storage.NewReader(r))
+	if err := tapFile.Read(); err != nil {
+		return nil, errors.Wrap(err, "unable to read TAP file")
+	}
+
+	tape := &TZX{}
+	for _, b := range tapFile.Blocks {
+		tape.blocks = append(tape.blocks, blocks.NewStandardSpeedData(b.Length, b.TapeData, tapPauseMs))
+	}
+	tape.header = newHeader(tape.blocks)
+
+	return tape, nil
+}
+
+// ExportTAP writes the StandardSpeedData and TurboSpeedData blocks to the
+// writer as a TAP file, in file order, with each block given as a length word
+// followed by its flag, data and checksum bytes.
+//
+// The TAP format holds no timing information, so the other blocks are
+// skipped. The number of skipped data and audio blocks (see Block.Category),
+// which hold data that is lost in the conversion, is returned.
+func (t TZX) ExportTAP(w io.Writer) (skipped int, err error) {
+	for i, block := range t.blocks {
+		var data []byte
+
+		switch b := block.(type) {
+		case *blocks.StandardSpeedData:
+			data = b.Bytes()
+		case *blocks.TurboSpeedData:
+			data = b.DataBlock
+		default:
+			switch block.Category() {
+			case types.CategoryData, types.CategoryAudio:
+				skipped++
+			}
+			continue
+		}
+
+		if len(data) > 0xffff {
+			return skipped, fmt.Errorf("block #%02d data length of %d bytes is too large for a TAP file", i+1, len(data))
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint16(len(data))); err != nil {
+			return skipped, errors.Wrap(err, "unable to write TAP block")
+		}
+		if _, err := w.Write(data); err != nil {
+			return skipped, errors.Wrap(err, "unable to write TAP block")
+		}
+	}
+
+	return skipped, nil
+}
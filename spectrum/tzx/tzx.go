@@ -54,12 +54,11 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/pkg/errors"
 
-	"github.com/mrcook/retroio/spectrum/basic"
 	"github.com/mrcook/retroio/spectrum/tap"
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
 	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
 	"github.com/mrcook/retroio/storage"
 )
@@ -71,13 +70,26 @@ const (
 
 // TZX files store the header information at the start of the file, followed
 // by zero or more data blocks. Some TZX files include an ArchiveInfo block,
-// which is always stored as the first block, directly after the header.
+// which should be the first block, directly after the header. This is stored
+// in the blocks slice, in position, as with all other blocks; concatenated
+// tapes may contain further ArchiveInfo blocks, one after each GlueBlock.
 type TZX struct {
-	reader *storage.Reader
+	reader        *storage.Reader
+	options       ReaderOptions
+	writerOptions WriterOptions
 
 	header
-	archive Block
-	blocks  []Block
+	headerRead bool
+	blocks     []Block
+	index      []BlockInfo
+}
+
+// BlockInfo records the position of a block within the TZX file.
+type BlockInfo struct {
+	ID     types.BlockType // Block ID
+	Offset int64           // Offset of the block ID byte from the start of the file
+	Size   int64           // Number of bytes used by the block, including the ID byte
+	Raw    []byte          // Bytes of the block, when read with the KeepRawBytes option
 }
 
 // Block is an interface for Tape data blocks
@@ -85,7 +97,10 @@ type Block interface {
 	Read(reader *storage.Reader) error
 	Id() types.BlockType
 	Name() string
+	Category() types.BlockCategory
 	BlockData() tap.Block
+	PauseMs() uint16
+	Size() int
 }
 
 // Header is the first block of data found in all TZX files.
@@ -99,8 +114,112 @@ type header struct {
 	MinorVersion uint8   // TZX minor revision number
 }
 
-func New(reader *storage.Reader) *TZX {
-	return &TZX{reader: reader}
+// ReaderOptions are used to configure how the tape blocks are read.
+type ReaderOptions struct {
+	// BlockFactory, when set, is called with the ID of each block before
+	// reading it, allowing custom block implementations to be used.
+	// If it returns nil then the default block for that ID is used.
+	BlockFactory func(id uint8) Block
+
+	// UnknownBlockPolicy sets how blocks with an unsupported ID are handled.
+	UnknownBlockPolicy UnknownBlockPolicy
+
+	// MaxPlaybackSteps limits the number of blocks played when following the
+	// flow control blocks, e.g. with LinearBlocks, protecting against tapes
+	// with infinite loops. When 0, a default of 1048576 blocks is used.
+	MaxPlaybackSteps int
+
+	// KeepRawBytes retains the original bytes of each block, as read from the
+	// file, which are available with RawBytes. ArchiveInfo blocks also keep
+	// them in their RawBytes field, so they can be written back out unchanged.
+	KeepRawBytes bool
+
+	// StrictValidation checks the structure of the tape after reading (see
+	// Validate), returning the first problem found as an error.
+	StrictValidation bool
+}
+
+// Option sets one of the ReaderOptions, and can be passed to New.
+type Option func(*ReaderOptions)
+
+// WithOptions replaces all the options with the given ReaderOptions. Any
+// options following it will modify these.
+func WithOptions(options ReaderOptions) Option {
+	return func(o *ReaderOptions) {
+		*o = options
+	}
+}
+
+// WithBlockFactory sets the BlockFactory option.
+func WithBlockFactory(factory func(id uint8) Block) Option {
+	return func(o *ReaderOptions) {
+		o.BlockFactory = factory
+	}
+}
+
+// WithUnknownBlockPolicy sets the UnknownBlockPolicy option.
+func WithUnknownBlockPolicy(policy UnknownBlockPolicy) Option {
+	return func(o *ReaderOptions) {
+		o.UnknownBlockPolicy = policy
+	}
+}
+
+// WithSkipUnknownBlocks skips blocks with an unsupported ID, using the
+// length given by the General Extension Rule.
+func WithSkipUnknownBlocks() Option {
+	return func(o *ReaderOptions) {
+		o.UnknownBlockPolicy = UnknownBlockSkipWithExtensionRule
+	}
+}
+
+// WithMaxPlaybackSteps sets the MaxPlaybackSteps option.
+func WithMaxPlaybackSteps(steps int) Option {
+	return func(o *ReaderOptions) {
+		o.MaxPlaybackSteps = steps
+	}
+}
+
+// WithRawCapture sets the KeepRawBytes option.
+func WithRawCapture() Option {
+	return func(o *ReaderOptions) {
+		o.KeepRawBytes = true
+	}
+}
+
+// WithStrictValidation sets the StrictValidation option.
+func WithStrictValidation() Option {
+	return func(o *ReaderOptions) {
+		o.StrictValidation = true
+	}
+}
+
+// UnknownBlockPolicy controls how blocks with an unknown or deprecated ID are handled.
+type UnknownBlockPolicy int
+
+const (
+	// UnknownBlockError stops reading and returns an error (the default).
+	UnknownBlockError UnknownBlockPolicy = iota
+
+	// UnknownBlockSkipWithExtensionRule skips the block using the length
+	// given in the 4 bytes following the ID.
+	UnknownBlockSkipWithExtensionRule
+
+	// UnknownBlockSkipOneByte skips only the block ID byte.
+	UnknownBlockSkipOneByte
+
+	// UnknownBlockCapture stores the block as an UnknownBlock, using the
+	// length given in the 4 bytes following the ID.
+	UnknownBlockCapture
+)
+
+// New returns a new TZX reader, configured with any of the given options.
+// Without options the default ReaderOptions are used.
+func New(reader *storage.Reader, opts ...Option) *TZX {
+	t := &TZX{reader: reader}
+	for _, opt := range opts {
+		opt(&t.options)
+	}
+	return t
 }
 
 // Read processes the header, and then each block on the tape.
@@ -113,9 +232,83 @@ func (t *TZX) Read() error {
 		return err
 	}
 
+	if t.options.StrictValidation {
+		if errs := t.Validate(); len(errs) > 0 {
+			return errors.Wrap(errs[0], "tape failed validation")
+		}
+	}
+
 	return nil
 }
 
+// ReadBlocksAndIndex processes the tape, as with Read, and returns the offset
+// and size of each block in the file, recorded while the blocks were read.
+// Every block is included, in file order, including any ArchiveInfo blocks.
+func (t *TZX) ReadBlocksAndIndex() ([]BlockInfo, error) {
+	if err := t.Read(); err != nil {
+		return nil, err
+	}
+	return t.index, nil
+}
+
+// RawBytes returns the bytes of the block at the given index (starting from
+// 0), exactly as they were read from the file, including the ID byte. This
+// requires the KeepRawBytes reader option; nil is returned when the bytes
+// were not kept, or blocks have since been added to or removed from the tape.
+func (t TZX) RawBytes(index int) []byte {
+	if len(t.index) != len(t.blocks) || index < 0 || index >= len(t.index) {
+		return nil
+	}
+	return t.index[index].Raw
+}
+
+// ReadIndex processes the tape header, then reads each block to record its
+// offset and size in the file, without keeping the blocks themselves. Each
+// block can then be read individually with BlockAt, which is useful for
+// large tapes where only some of the blocks are needed.
+func (t *TZX) ReadIndex() ([]BlockInfo, error) {
+	if err := t.readHeader(); err != nil {
+		return nil, err
+	}
+
+	t.blocks, t.index = nil, nil
+	for {
+		_, info, err := t.readBlock()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		t.index = append(t.index, info)
+	}
+
+	return t.index, nil
+}
+
+// BlockAt returns the block at the given index (starting from 0). When the
+// tape has been read with Read, the block is returned directly. Otherwise,
+// after ReadIndex, the reader is moved to the offset of the block and just
+// that block is read, which requires the tape to be read from an
+// io.ReadSeeker, such as an os.File, positioned at the start of the file.
+func (t *TZX) BlockAt(index int) (Block, error) {
+	if index >= 0 && index < len(t.blocks) {
+		return t.blocks[index], nil
+	}
+	if index < 0 || index >= len(t.index) {
+		return nil, fmt.Errorf("block index %d out of range, tape has %d blocks", index, len(t.index))
+	}
+
+	if err := t.reader.SeekTo(t.index[index].Offset); err != nil {
+		return nil, errors.Wrap(err, "unable to seek to the block")
+	}
+	block, _, err := t.readBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
 // readHeader reads the tape header data and validates that the format is correct.
 func (t *TZX) readHeader() error {
 	t.header = header{}
@@ -127,55 +320,216 @@ func (t *TZX) readHeader() error {
 	if err := t.header.valid(); err != nil {
 		return err
 	}
+	t.headerRead = true
 
 	return nil
 }
 
 // readBlocks processes each TZX block on the tape.
 func (t *TZX) readBlocks() error {
+	for {
+		block, info, err := t.readBlock()
+		if err == io.EOF {
+			break // no problems, we're done!
+		} else if err != nil {
+			return err
+		}
+
+		t.index = append(t.index, info)
+		t.blocks = append(t.blocks, block)
+	}
+	return nil
+}
+
+// NextBlock reads and returns the next block on the tape, reading the header
+// first if needed, and returns io.EOF when there are no more blocks. This is
+// a streaming alternative to Read: the blocks are not stored on the tape, so
+// large tapes can be processed one block at a time.
+func (t *TZX) NextBlock() (Block, error) {
+	if !t.headerRead {
+		if err := t.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	block, _, err := t.readBlock()
+	return block, err
+}
+
+// readBlock reads the next block, along with its position in the file,
+// returning io.EOF at the end of the tape. Blocks skipped by the
+// UnknownBlockPolicy are passed over.
+func (t *TZX) readBlock() (Block, BlockInfo, error) {
 	for {
 		blockID, err := t.reader.PeekByte()
 		if err != nil {
-			if err == io.EOF {
-				break // no problems, we're done!
-			}
-			return err
+			return nil, BlockInfo{}, err
 		}
 
-		block, err := newFromBlockID(blockID)
+		block, err := t.newBlock(blockID)
 		if err != nil {
-			return err
+			block, err = t.unknownBlock(err)
+			if err != nil {
+				return nil, BlockInfo{}, err
+			}
+			if block == nil {
+				continue // the block was skipped
+			}
+		}
+
+		if t.options.KeepRawBytes {
+			t.reader.StartCapture()
 		}
 
+		offset := t.reader.Offset()
 		if err := block.Read(t.reader); err != nil {
-			return errors.Wrap(err, "error reading TZX block")
+			t.reader.StopCapture()
+			return nil, BlockInfo{}, errors.Wrap(err, "error reading TZX block")
 		}
 
-		if block.Id() == types.ArchiveInfo {
-			t.archive = block
-		} else {
-			t.blocks = append(t.blocks, block)
+		var raw []byte
+		if t.options.KeepRawBytes {
+			raw = t.reader.StopCapture()
+			if archive, ok := block.(*blocks.ArchiveInfo); ok {
+				archive.RawBytes = raw
+			}
+		}
+
+		info := BlockInfo{
+			ID:     block.Id(),
+			Offset: offset,
+			Size:   t.reader.Offset() - offset,
+			Raw:    raw,
+		}
+		return block, info, nil
+	}
+}
+
+// Version returns the TZX specification revision given in the tape header.
+func (t TZX) Version() (major, minor uint8) {
+	return t.MajorVersion, t.MinorVersion
+}
+
+// IsVersionSupported reports whether the tape revision can be fully read by
+// this package; all earlier v1.x revisions are a subset of the supported one.
+func (t TZX) IsVersionSupported() bool {
+	return t.MajorVersion == supportedMajorVersion && t.MinorVersion <= supportedMinorVersion
+}
+
+// BlockSignature returns the ordered list of block IDs found on the tape,
+// which can be used to compare the structure (not content) of two tapes.
+func (t TZX) BlockSignature() []uint8 {
+	var ids []uint8
+	for _, block := range t.blocks {
+		ids = append(ids, uint8(block.Id()))
+	}
+	return ids
+}
+
+// IsStandardSpeedOnly reports whether all blocks containing tape data are
+// StandardSpeedData blocks, and so can be loaded using the ROM routines.
+// Flow control, text, and pause blocks are allowed.
+func (t TZX) IsStandardSpeedOnly() bool {
+	for _, block := range t.blocks {
+		switch block.Id() {
+		case types.TurboSpeedData, types.PureTone, types.SequenceOfPulses, types.PureData,
+			types.DirectRecording, types.CswRecording, types.GeneralizedData, types.Snapshot:
+			return false
+		}
+	}
+	return true
+}
+
+// ReplaceBlockData replaces the data of the block at the given index (starting
+// from 0, and including any ArchiveInfo block), keeping the flag byte and
+// updating the block length and checksum values.
+// Only StandardSpeedData and TurboSpeedData blocks can be updated.
+func (t *TZX) ReplaceBlockData(index int, data []byte) error {
+	if index < 0 || index >= len(t.blocks) {
+		return fmt.Errorf("block index %d out of range", index)
+	}
+
+	block, ok := t.blocks[index].(interface{ SetData([]byte) error })
+	if !ok {
+		return fmt.Errorf("unable to replace the data of a %s block", t.blocks[index].Name())
+	}
+
+	return block.SetData(data)
+}
+
+// ArchiveInfo returns the first ArchiveInfo block found on the tape, which
+// should be the first block. Concatenated tapes may contain one for each
+// joined file, which are given by Segments, or by the tapes from Split.
+func (t TZX) ArchiveInfo() (*blocks.ArchiveInfo, bool) {
+	for _, block := range t.blocks {
+		if archive, ok := block.(*blocks.ArchiveInfo); ok {
+			return archive, true
+		}
+	}
+	return nil, false
+}
+
+// Blocks returns all blocks in the order they appear on the tape, including
+// any ArchiveInfo blocks. The returned slice is a copy, but the blocks
+// themselves are shared with the tape.
+func (t TZX) Blocks() []Block {
+	return append([]Block(nil), t.blocks...)
+}
+
+// ForEachBlock calls fn with the index (starting from 0) and block, for each
+// block in the order they appear on the tape, including any ArchiveInfo
+// blocks. Iteration stops at the first error returned by fn.
+func (t TZX) ForEachBlock(fn func(i int, b Block) error) error {
+	for i, block := range t.blocks {
+		if err := fn(i, block); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// DisplayGeometry prints the metadata, archive info, data blocks, etc.
-func (t TZX) DisplayGeometry() {
-	// TODO: update `block`'s to store their index number
-	blockCountOffset := 1 // Block #'s start from 1
+// DataBlocks returns the blocks that carry tape data, in the order they appear
+// on the tape: the standard, turbo, pure data, direct recording, CSW recording
+// and generalized data blocks. Timing, flow control and metadata blocks are
+// not included.
+func (t TZX) DataBlocks() []Block {
+	return t.BlocksByID(types.StandardSpeedData, types.TurboSpeedData, types.PureData,
+		types.DirectRecording, types.CswRecording, types.GeneralizedData)
+}
 
-	if t.archive != nil {
-		// Archive counts as a normal block, but it is not stored in blocks slice
-		blockCountOffset += 1
+// BlocksByID returns the blocks with any of the given IDs, in the order they
+// appear on the tape.
+func (t TZX) BlocksByID(ids ...types.BlockType) []Block {
+	var list []Block
+	for _, block := range t.blocks {
+		for _, id := range ids {
+			if block.Id() == id {
+				list = append(list, block)
+				break
+			}
+		}
+	}
+	return list
+}
 
-		fmt.Println("ARCHIVE INFORMATION (BLOCK #1):")
-		fmt.Println(t.archive)
+// DisplayGeometry prints the metadata, archive info, data blocks, etc.
+func (t TZX) DisplayGeometry() {
+	// Block #'s start from 1
+	archiveIndex := -1
+	for i, block := range t.blocks {
+		if block.Id() == types.ArchiveInfo {
+			archiveIndex = i
+			fmt.Printf("ARCHIVE INFORMATION (BLOCK #%d):\n", i+1)
+			fmt.Println(block)
+			break
+		}
 	}
 
 	fmt.Println("DATA BLOCKS:")
 	for i, block := range t.blocks {
-		fmt.Printf("#%02d %s\n", i+blockCountOffset, block)
+		if i != archiveIndex {
+			fmt.Printf("#%02d %s\n", i+1, block)
+		}
 	}
 
 	fmt.Println()
@@ -190,44 +544,11 @@ func (t TZX) DisplayGeometry() {
 	fmt.Println()
 }
 
-// DisplayBASIC outputs all BASIC programs
+// DisplayBASIC outputs all BASIC programs, as listed by ListBASIC.
 func (t TZX) DisplayBASIC() {
-	isProgram := false
-	filename := ""
-
-	// TODO: update `block`'s to store their index number
-	// Archive counts as a normal block, but it is not stored in blocks slice
-	blockCountOffset := 1 // Block #'s start from 1
-	if t.archive != nil {
-		blockCountOffset += 1
-	}
-
-	listing := ""
-	for i, block := range t.blocks {
-		if block.BlockData() == nil {
-			continue
-		}
-		blk := block.BlockData()
-
-		if isProgram == true {
-			listing += fmt.Sprintf("BLK#%02d: %s\n", i+blockCountOffset, filename)
+	// programs that fail to decode are reported within the listing
+	listing, _ := t.ListBASIC()
 
-			program, err := basic.Decode(blk.BlockData())
-			if err != nil {
-				listing += fmt.Sprintf("    %s\n", err)
-				continue
-			}
-
-			for _, line := range program {
-				listing += line
-			}
-			listing += "\n"
-			isProgram = false
-		} else if blk.Id() == 0 && blk.Filename() != "" {
-			filename = strings.Trim(block.BlockData().Filename(), " ")
-			isProgram = true
-		}
-	}
 	if len(listing) > 0 {
 		fmt.Println("BASIC PROGRAMS:")
 		fmt.Println()
@@ -237,6 +558,15 @@ func (t TZX) DisplayBASIC() {
 	}
 }
 
+// newHeader returns the header for a new tape, using the minimum revision
+// able to represent the blocks.
+func newHeader(tapeBlocks []Block) header {
+	h := header{Terminator: 0x1a}
+	h.MajorVersion, h.MinorVersion = minimumVersion(tapeBlocks)
+	copy(h.Signature[:], "ZXTape!")
+	return h
+}
+
 // Validates the TZX header data.
 func (h header) valid() error {
 	var validationError error
@@ -0,0 +1,251 @@
+package tzx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+	"github.com/mrcook/retroio/storage"
+)
+
+func TestUnknownBlockOptions(t *testing.T) {
+	// block 0x60 is not in the specification, but follows the extension rule
+	unknown := []byte{0x60, 0x02, 0x00, 0x00, 0x00, 0xaa, 0xbb}
+	raw := tzxFile(20, standardBlock, unknown, standardBlock)
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+		want    int // number of blocks read
+	}{
+		{"default", nil, true, 0},
+		{"skip unknown blocks", []Option{WithSkipUnknownBlocks()}, false, 2},
+		{"capture policy", []Option{WithUnknownBlockPolicy(UnknownBlockCapture)}, false, 3},
+		{"reader options", []Option{WithOptions(ReaderOptions{UnknownBlockPolicy: UnknownBlockCapture})}, false, 3},
+		{"options after reader options", []Option{WithOptions(ReaderOptions{UnknownBlockPolicy: UnknownBlockCapture}), WithSkipUnknownBlocks()}, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := New(storage.NewReader(bytes.NewReader(raw)), tt.opts...)
+			err := tape.Read()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error for the unknown block")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tape.Blocks()) != tt.want {
+				t.Fatalf("expected %d blocks, got %d", tt.want, len(tape.Blocks()))
+			}
+			if tt.want == 3 {
+				if _, ok := tape.Blocks()[1].(*blocks.UnknownBlock); !ok {
+					t.Errorf("expected an UnknownBlock, got %T", tape.Blocks()[1])
+				}
+			}
+		})
+	}
+}
+
+// wrappedStandardBlock is a custom block implementation, which counts the
+// number of standard speed data blocks read.
+type wrappedStandardBlock struct {
+	*blocks.StandardSpeedData
+	reads *int
+}
+
+func (w *wrappedStandardBlock) Read(reader *storage.Reader) error {
+	*w.reads++
+	return w.StandardSpeedData.Read(reader)
+}
+
+func TestBlockFactory(t *testing.T) {
+	reads := 0
+	factory := func(id uint8) Block {
+		if id != 0x10 {
+			return nil
+		}
+		return &wrappedStandardBlock{StandardSpeedData: &blocks.StandardSpeedData{}, reads: &reads}
+	}
+
+	raw := tzxFile(20, standardBlock, []byte{0x20, 0x00, 0x00}, standardBlock)
+	tape := readTape(t, raw, WithBlockFactory(factory))
+
+	if reads != 2 {
+		t.Errorf("expected the factory blocks to read 2 blocks, got %d", reads)
+	}
+	tapeBlocks := tape.Blocks()
+	if len(tapeBlocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(tapeBlocks))
+	}
+	for _, i := range []int{0, 2} {
+		w, ok := tapeBlocks[i].(*wrappedStandardBlock)
+		if !ok {
+			t.Fatalf("block %d: expected a wrapped standard block, got %T", i, tapeBlocks[i])
+		}
+		if !bytes.Equal(w.Bytes(), []byte{0xff, 0x01, 0x02, 0x03, 0xff}) {
+			t.Errorf("block %d: expected the wrapped block data to be read, got % x", i, w.Bytes())
+		}
+	}
+	if _, ok := tapeBlocks[1].(*blocks.PauseTapeCommand); !ok {
+		t.Errorf("expected the default block for a nil factory result, got %T", tapeBlocks[1])
+	}
+}
+
+func TestReadSnapshotFollowedByBlocks(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/snapshot.tzx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := readTape(t, raw).Blocks()
+	if len(list) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(list))
+	}
+
+	snapshot, ok := list[0].(*blocks.Snapshot)
+	if !ok {
+		t.Fatalf("expected a Snapshot block, got %T", list[0])
+	}
+	if snapshot.SnapshotType != 0x01 || !bytes.Equal(snapshot.Data, []byte{1, 2, 3, 4}) {
+		t.Errorf("expected a .SNA snapshot of 01 02 03 04, got type 0x%02x, data % x", snapshot.SnapshotType, snapshot.Data)
+	}
+
+	if text, ok := list[1].(*blocks.TextDescription); !ok || string(text.Description) != "next" {
+		t.Errorf("expected the text description 'next' after the snapshot, got %v", list[1])
+	}
+	if data, ok := list[2].(*blocks.StandardSpeedData); !ok || !bytes.Equal(data.Bytes(), []byte{0xff, 1, 2, 3, 0xff}) {
+		t.Errorf("expected the standard data block to be read, got %v", list[2])
+	}
+}
+
+func TestReadEmulationInfoFollowedByBlocks(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/emulation_info.tzx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := readTape(t, raw).Blocks()
+	if len(list) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(list))
+	}
+
+	info, ok := list[0].(*blocks.EmulationInfo)
+	if !ok {
+		t.Fatalf("expected an EmulationInfo block, got %T", list[0])
+	}
+	if info.Flags != 0x0201 || info.RefreshDelay != 1 || info.InterruptFrequency != 50 {
+		t.Errorf("expected flags 0x0201, refresh delay 1 and 50Hz, got 0x%04x, %d and %dHz", info.Flags, info.RefreshDelay, info.InterruptFrequency)
+	}
+
+	if text, ok := list[1].(*blocks.TextDescription); !ok || string(text.Description) != "next" {
+		t.Errorf("expected the text description 'next' after the emulation info, got %v", list[1])
+	}
+	if data, ok := list[2].(*blocks.StandardSpeedData); !ok || !bytes.Equal(data.Bytes(), []byte{0xff, 1, 2, 3, 0xff}) {
+		t.Errorf("expected the standard data block to be read, got %v", list[2])
+	}
+}
+
+func TestBlockSignature(t *testing.T) {
+	raw := tzxFile(20,
+		[]byte{0x30, 0x02, 'h', 'i'},
+		standardBlock,
+		pureTone(2168, 10),
+		[]byte{0x20, 0x64, 0x00},
+		standardBlock,
+	)
+
+	want := []uint8{0x30, 0x10, 0x12, 0x20, 0x10}
+	if got := readTape(t, raw).BlockSignature(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected block IDs % x, got % x", want, got)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/snapshot.tzx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tape := readTape(t, raw)
+	if major, minor := tape.Version(); major != 1 || minor != 10 {
+		t.Errorf("expected v1.10, got v%d.%02d", major, minor)
+	}
+	if !tape.IsVersionSupported() {
+		t.Error("expected v1.10 to be supported")
+	}
+
+	tests := []struct {
+		minor uint8
+		want  bool
+	}{
+		{20, true},
+		{21, false},
+	}
+	for _, tt := range tests {
+		tape := readTape(t, tzxFile(tt.minor, standardBlock))
+		if got := tape.IsVersionSupported(); got != tt.want {
+			t.Errorf("v1.%02d: expected supported to be %v, got %v", tt.minor, tt.want, got)
+		}
+	}
+}
+
+func TestReadBlocksAndIndex(t *testing.T) {
+	raw := tzxFile(20, archiveTitle("Game"), standardBlock, pureTone(2168, 10), []byte{0x20, 0x64, 0x00})
+
+	tape := New(storage.NewReader(bytes.NewReader(raw)))
+	index, err := tape.ReadBlocksAndIndex()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list := tape.Blocks()
+	if len(index) != len(list) {
+		t.Fatalf("expected an index entry for each of the %d blocks, got %d", len(list), len(index))
+	}
+
+	offset := int64(10)
+	for i, info := range index {
+		if info.ID != list[i].Id() {
+			t.Errorf("block %d: expected ID 0x%02x, got 0x%02x", i, list[i].Id(), info.ID)
+		}
+		if info.Offset != offset {
+			t.Errorf("block %d: expected offset %d, got %d", i, offset, info.Offset)
+		}
+		if sized, ok := list[i].(interface{ Size() int }); !ok || int64(sized.Size()) != info.Size {
+			t.Errorf("block %d: expected the index size %d to match the block size", i, info.Size)
+		}
+		if raw[info.Offset] != uint8(list[i].Id()) {
+			t.Errorf("block %d: expected the offset to point to the block ID", i)
+		}
+		offset += info.Size
+	}
+	if offset != int64(len(raw)) {
+		t.Errorf("expected the blocks to end at the end of the file, %d, got %d", len(raw), offset)
+	}
+}
+
+func TestIsStandardSpeedOnly(t *testing.T) {
+	text := []byte{0x30, 0x02, 'h', 'i'}
+	pause := []byte{0x20, 0x64, 0x00}
+	jump := []byte{0x23, 0x02, 0x00}
+
+	tests := []struct {
+		name string
+		raw  []byte
+		want bool
+	}{
+		{"standard blocks with text, pauses, and flow control", tzxFile(20, text, jump, standardBlock, standardBlock, pause), true},
+		{"includes a turbo block", tzxFile(20, standardBlock, turboBlock(0xff, []byte{1, 2, 3}, 0xff)), false},
+		{"includes a pure tone", tzxFile(20, pureTone(2168, 10), standardBlock), false},
+	}
+
+	for _, tt := range tests {
+		if got := readTape(t, tt.raw).IsStandardSpeedOnly(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package tzx
+
+import (
+	"github.com/mrcook/retroio/spectrum/tzx/blocks/types"
+)
+
+// The oldest TZX revision reported by MinimumVersion. This is the revision
+// which introduced the General Extension Rule, and is the baseline supported
+// by all current TZX readers.
+const (
+	baseMajorVersion = 1
+	baseMinorVersion = 10
+)
+
+// blockMinorVersions lists the blocks that were introduced after the base
+// revision, along with the v1.x revision in which they were added.
+var blockMinorVersions = map[types.BlockType]uint8{
+	types.StopTapeWhen48kMode: 13,
+	types.CswRecording:        20,
+	types.GeneralizedData:     20,
+	types.SetSignalLevel:      20,
+}
+
+// MinimumVersion returns the lowest TZX revision able to represent all the
+// blocks on the tape, which can be used for the header when writing the tape.
+// Blocks from the base v1.10 revision, or earlier, report v1.10.
+func (t TZX) MinimumVersion() (major, minor uint8) {
+	return minimumVersion(t.blocks)
+}
+
+// minimumVersion returns the lowest TZX revision able to represent the blocks.
+func minimumVersion(tapeBlocks []Block) (major, minor uint8) {
+	major, minor = baseMajorVersion, baseMinorVersion
+
+	for _, block := range tapeBlocks {
+		if v, ok := blockMinorVersions[block.Id()]; ok && v > minor {
+			minor = v
+		}
+	}
+
+	return major, minor
+}
@@ -0,0 +1,214 @@
+package tzx
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// Sample values used for the high and low signal levels of the 8-bit PCM data.
+const (
+	wavHighLevel = 0xc0
+	wavLowLevel  = 0x40
+)
+
+// WAV file header for mono 8-bit PCM data.
+type wavHeader struct {
+	ChunkID       [4]byte // `RIFF`
+	ChunkSize     uint32  // 36 + data size
+	Format        [4]byte // `WAVE`
+	FmtChunkID    [4]byte // `fmt `
+	FmtChunkSize  uint32  // 16 for PCM
+	AudioFormat   uint16  // 1 = PCM
+	Channels      uint16  // 1 = mono
+	SampleRate    uint32  // Samples per second
+	ByteRate      uint32  // SampleRate * Channels * BitsPerSample/8
+	BlockAlign    uint16  // Channels * BitsPerSample/8
+	BitsPerSample uint16  // 8
+	DataChunkID   [4]byte // `data`
+	DataSize      uint32  // Number of bytes of sample data
+}
+
+// ExportWAV renders the tape as a mono 8-bit PCM WAV file, at the given
+// sample rate (e.g. 44100 Hz). The pulses are generated by StreamPulses.
+//
+// The samples are streamed to the writer, rather than buffered. When the
+// writer is an io.WriteSeeker, such as an os.File, the tape is played once,
+// and the data size in the WAV header is written after the samples, by
+// seeking back to the start of the header. For other writers, or when the
+// writer can not seek, the tape is played twice, first to calculate the
+// data size.
+func (t TZX) ExportWAV(w io.Writer, sampleRate int) error {
+	if sampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	if ws, ok := w.(io.WriteSeeker); ok {
+		if start, err := ws.Seek(0, io.SeekCurrent); err == nil {
+			return t.exportWAVSeeker(ws, start, sampleRate)
+		}
+	}
+
+	var size uint64
+	err := t.StreamPulses(newSampler(sampleRate, func(level bool, count uint64) error {
+		size += count
+		return nil
+	}))
+	if err != nil {
+		return err
+	}
+	if size > maxWAVDataSize {
+		return fmt.Errorf("tape is too long for a WAV file at %d Hz", sampleRate)
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := binary.Write(bw, binary.LittleEndian, newWAVHeader(sampleRate, uint32(size))); err != nil {
+		return errors.Wrap(err, "unable to write WAV header")
+	}
+	if _, err := t.writeWAVData(bw, sampleRate); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// exportWAVSeeker writes the WAV file with a placeholder header at the start
+// offset, followed by the samples, then seeks back to write the header with
+// the data size. The writer is left positioned after the samples.
+func (t TZX) exportWAVSeeker(ws io.WriteSeeker, start int64, sampleRate int) error {
+	bw := bufio.NewWriter(ws)
+	if err := binary.Write(bw, binary.LittleEndian, newWAVHeader(sampleRate, 0)); err != nil {
+		return errors.Wrap(err, "unable to write WAV header")
+	}
+	size, err := t.writeWAVData(bw, sampleRate)
+	if err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if size > maxWAVDataSize {
+		return fmt.Errorf("tape is too long for a WAV file at %d Hz", sampleRate)
+	}
+
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Wrap(err, "unable to seek to the WAV header")
+	}
+	if _, err := ws.Seek(start, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to seek to the WAV header")
+	}
+	if err := binary.Write(ws, binary.LittleEndian, newWAVHeader(sampleRate, uint32(size))); err != nil {
+		return errors.Wrap(err, "unable to write WAV header")
+	}
+	if _, err := ws.Seek(end, io.SeekStart); err != nil {
+		return errors.Wrap(err, "unable to seek to the end of the WAV data")
+	}
+
+	return nil
+}
+
+// maxWAVDataSize is the largest data size that fits in the WAV chunk size.
+const maxWAVDataSize = 0xffffffff - 36
+
+// newWAVHeader returns the WAV header for the number of bytes of sample data.
+func newWAVHeader(sampleRate int, size uint32) wavHeader {
+	header := wavHeader{
+		ChunkSize:     36 + size,
+		FmtChunkSize:  16,
+		AudioFormat:   1,
+		Channels:      1,
+		SampleRate:    uint32(sampleRate),
+		ByteRate:      uint32(sampleRate),
+		BlockAlign:    1,
+		BitsPerSample: 8,
+		DataSize:      size,
+	}
+	copy(header.ChunkID[:], "RIFF")
+	copy(header.Format[:], "WAVE")
+	copy(header.FmtChunkID[:], "fmt ")
+	copy(header.DataChunkID[:], "data")
+
+	return header
+}
+
+// writeWAVData plays the tape, writing each sample, and returns the number
+// of samples written.
+func (t TZX) writeWAVData(bw *bufio.Writer, sampleRate int) (uint64, error) {
+	var size uint64
+
+	err := t.StreamPulses(newSampler(sampleRate, func(level bool, count uint64) error {
+		value := byte(wavLowLevel)
+		if level {
+			value = wavHighLevel
+		}
+		for i := uint64(0); i < count; i++ {
+			if err := bw.WriteByte(value); err != nil {
+				return err
+			}
+		}
+		size += count
+		return nil
+	}))
+	if err != nil {
+		return size, errors.Wrap(err, "unable to write WAV data")
+	}
+
+	return size, nil
+}
+
+// newSampler returns a pulse handler for StreamPulses that converts each
+// pulse to a number of samples at the sample rate, calling fn with the level
+// and sample count. The position is tracked in T-states so that rounding
+// errors do not accumulate over the length of the tape.
+func newSampler(sampleRate int, fn func(level bool, count uint64) error) func(Pulse) error {
+	var tStates, samples uint64
+
+	return func(p Pulse) error {
+		tStates += uint64(p.TStates)
+		end := tStates * uint64(sampleRate) / blocks.TStatesPerSecond
+		count := end - samples
+		samples = end
+
+		if count == 0 {
+			return nil
+		}
+		return fn(p.Level, count)
+	}
+}
+
+// RenderBlock renders a single block as mono 8-bit PCM samples, at the given
+// sample rate, using the same levels as ExportWAV. The signal starts at the
+// given level, and the level at the end of the block is returned, so blocks
+// can be rendered one after another with the correct edges. Blocks which
+// produce no signal, such as the flow control and text blocks, return no
+// samples and leave the level unchanged. As each block is rounded to whole
+// samples, the result may differ from ExportWAV by a sample per block.
+func RenderBlock(b Block, sampleRate int, startLevel bool) (samples []byte, endLevel bool, err error) {
+	if sampleRate <= 0 {
+		return nil, startLevel, fmt.Errorf("invalid sample rate: %d", sampleRate)
+	}
+
+	p := &pulseStream{PulseState: PulseState{Level: startLevel}}
+	p.emit = newSampler(sampleRate, func(level bool, count uint64) error {
+		value := byte(wavLowLevel)
+		if level {
+			value = wavHighLevel
+		}
+		for i := uint64(0); i < count; i++ {
+			samples = append(samples, value)
+		}
+		return nil
+	})
+
+	if err := p.block(b); err != nil {
+		return nil, startLevel, err
+	}
+
+	return samples, p.Level, nil
+}
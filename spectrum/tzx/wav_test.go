@@ -0,0 +1,106 @@
+package tzx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+)
+
+// memoryWriteSeeker is an in-memory io.WriteSeeker, counting the seeks made.
+type memoryWriteSeeker struct {
+	data  []byte
+	pos   int
+	seeks int
+}
+
+func (m *memoryWriteSeeker) Write(p []byte) (int, error) {
+	if need := m.pos + len(p); need > len(m.data) {
+		m.data = append(m.data, make([]byte, need-len(m.data))...)
+	}
+	copy(m.data[m.pos:], p)
+	m.pos += len(p)
+	return len(p), nil
+}
+
+func (m *memoryWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	m.seeks++
+	switch whence {
+	case io.SeekStart:
+		m.pos = int(offset)
+	case io.SeekCurrent:
+		m.pos += int(offset)
+	default:
+		return 0, errors.New("unsupported whence")
+	}
+	return int64(m.pos), nil
+}
+
+// countingDiscard discards everything written to it, counting the bytes.
+type countingDiscard struct{ n int }
+
+func (c *countingDiscard) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return len(p), nil
+}
+
+func TestExportWAVWriteSeeker(t *testing.T) {
+	tape := readTape(t, tzxFile(20, pureTone(2168, 100), standardBlock, []byte{0x20, 0x64, 0x00}))
+
+	var buf bytes.Buffer
+	if err := tape.ExportWAV(&buf, 44100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the header is written after some existing data, so its offset is used
+	ws := &memoryWriteSeeker{data: []byte("xyz"), pos: 3}
+	if err := tape.ExportWAV(ws, 44100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ws.seeks == 0 {
+		t.Error("expected the WAV header to be written by seeking back")
+	}
+	if ws.pos != len(ws.data) {
+		t.Errorf("expected the writer to be left at the end of the data, at %d, got %d", len(ws.data), ws.pos)
+	}
+	if !bytes.Equal(ws.data[3:], buf.Bytes()) {
+		t.Error("expected the same WAV file as written to a plain writer")
+	}
+
+	size := binary.LittleEndian.Uint32(buf.Bytes()[40:44])
+	if int(size) != buf.Len()-44 {
+		t.Errorf("expected a data size of %d, got %d", buf.Len()-44, size)
+	}
+	if chunkSize := binary.LittleEndian.Uint32(buf.Bytes()[4:8]); chunkSize != 36+size {
+		t.Errorf("expected a chunk size of %d, got %d", 36+size, chunkSize)
+	}
+}
+
+func TestExportWAVLongTapeIsStreamed(t *testing.T) {
+	// about 100 seconds of pilot tone
+	raw := tzxFile(20,
+		[]byte{0x24, 0x64, 0x00}, // loop start, 100 repetitions
+		pureTone(2168, 1600),
+		[]byte{0x25}, // loop end
+	)
+	tape := readTape(t, raw)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	w := &countingDiscard{}
+	if err := tape.ExportWAV(w, 44100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runtime.ReadMemStats(&after)
+	if w.n < 4*1024*1024 {
+		t.Fatalf("expected a WAV file of over 4MB, got %d bytes", w.n)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > uint64(w.n/10) {
+		t.Errorf("expected the samples to be streamed, but %d bytes were allocated for a %d byte file", allocated, w.n)
+	}
+}
@@ -0,0 +1,128 @@
+package tzx
+
+import (
+	"fmt"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// webPayloadLimit is the maximum size of a block payload included directly
+// in the web model; larger payloads must be fetched using BlockPayload.
+const webPayloadLimit = 1024
+
+// WebTape is a JSON friendly representation of the tape, for use by web
+// front-ends. Byte slices are encoded as base64 strings by `encoding/json`.
+type WebTape struct {
+	Version  string            `json:"version"`
+	Archive  []WebArchiveEntry `json:"archive,omitempty"`
+	Duration float64           `json:"duration"` // seconds
+	Blocks   []WebBlock        `json:"blocks"`
+}
+
+// WebArchiveEntry is a single text string from the ArchiveInfo block.
+type WebArchiveEntry struct {
+	Type    uint8  `json:"type"`
+	Heading string `json:"heading"`
+	Text    string `json:"text"`
+}
+
+// webArchiveEntries returns the text strings of the ArchiveInfo block, or
+// nil when the tape has no archive info.
+func (t TZX) webArchiveEntries() []WebArchiveEntry {
+	archive, ok := t.ArchiveInfo()
+	if !ok {
+		return nil
+	}
+
+	var entries []WebArchiveEntry
+	for _, text := range archive.Strings {
+		entries = append(entries, WebArchiveEntry{
+			Type:    text.TypeID,
+			Heading: text.Heading(),
+			Text:    text.String(),
+		})
+	}
+	return entries
+}
+
+// WebBlock contains the details of a single block. Small payloads are included
+// in full, while large ones only give their size, with the block index being
+// used to fetch them later.
+type WebBlock struct {
+	Index       int     `json:"index"`
+	ID          uint8   `json:"id"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	Duration    float64 `json:"duration"` // seconds, including any pause
+	PayloadSize int     `json:"payload_size"`
+	Payload     []byte  `json:"payload,omitempty"`
+	PayloadLazy bool    `json:"payload_lazy,omitempty"`
+}
+
+// ToWebModel returns the tape metadata, block list, and durations as a
+// struct that can be serialized to JSON.
+func (t TZX) ToWebModel() WebTape {
+	tape := WebTape{
+		Version:  fmt.Sprintf("%d.%d", t.MajorVersion, t.MinorVersion),
+		Archive:  t.webArchiveEntries(),
+		Duration: t.Duration().Seconds(),
+		Blocks:   []WebBlock{},
+	}
+
+	for i, block := range t.blocks {
+		tStates, pause := blockTiming(block)
+		duration := tStatesToDuration(tStates) + msToDuration(pause)
+
+		webBlock := WebBlock{
+			Index:       i,
+			ID:          uint8(block.Id()),
+			Name:        block.Name(),
+			Category:    BlockCategory(block),
+			Description: fmt.Sprint(block),
+			Duration:    duration.Seconds(),
+		}
+
+		payload := blockPayload(block)
+		webBlock.PayloadSize = len(payload)
+		if len(payload) > webPayloadLimit {
+			webBlock.PayloadLazy = true
+		} else if len(payload) > 0 {
+			webBlock.Payload = payload
+		}
+
+		tape.Blocks = append(tape.Blocks, webBlock)
+	}
+
+	return tape
+}
+
+// BlockPayload returns the raw data of the block at the given index (starting
+// from 0, and including any ArchiveInfo block), or nil if it has no data.
+func (t TZX) BlockPayload(index int) []byte {
+	if index < 0 || index >= len(t.blocks) {
+		return nil
+	}
+	return blockPayload(t.blocks[index])
+}
+
+// blockPayload returns the data bytes stored in a block.
+func blockPayload(block Block) []byte {
+	switch b := block.(type) {
+	case *blocks.StandardSpeedData:
+		return b.Bytes()
+	case *blocks.TurboSpeedData:
+		return b.DataBlock
+	case *blocks.PureData:
+		return b.DataBlock
+	case *blocks.DirectRecording:
+		return b.Data
+	case *blocks.CswRecording:
+		return b.Data
+	case *blocks.CustomInfo:
+		return b.Info
+	case *blocks.Snapshot:
+		return b.Data
+	}
+	return nil
+}
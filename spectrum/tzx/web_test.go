@@ -0,0 +1,76 @@
+package tzx
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestToWebModelJSON(t *testing.T) {
+	large := turboBlock(0xff, make([]byte, 1100), 0xff)
+	tape := readTape(t, tzxFile(20, archiveTitle("Games"), standardBlock, large))
+
+	encoded, err := json.Marshal(tape.ToWebModel())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var model map[string]interface{}
+	if err := json.Unmarshal(encoded, &model); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := jsonKeys(model); !reflect.DeepEqual(got, []string{"archive", "blocks", "duration", "version"}) {
+		t.Errorf("unexpected tape keys: %v", got)
+	}
+	if model["version"] != "1.20" {
+		t.Errorf("expected version 1.20, got %v", model["version"])
+	}
+	if duration, ok := model["duration"].(float64); !ok || duration <= 0 {
+		t.Errorf("expected a positive tape duration, got %v", model["duration"])
+	}
+
+	archive := model["archive"].([]interface{})
+	want := map[string]interface{}{"type": 0.0, "heading": "Title", "text": "Games"}
+	if len(archive) != 1 || !reflect.DeepEqual(archive[0], want) {
+		t.Errorf("expected archive entries of [%v], got %v", want, archive)
+	}
+
+	webBlocks := model["blocks"].([]interface{})
+	if len(webBlocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(webBlocks))
+	}
+
+	small := webBlocks[1].(map[string]interface{})
+	if got := jsonKeys(small); !reflect.DeepEqual(got, []string{"category", "description", "duration", "id", "index", "name", "payload", "payload_size"}) {
+		t.Errorf("unexpected small block keys: %v", got)
+	}
+	if small["index"] != 1.0 || small["id"] != 16.0 || small["category"] != "data" {
+		t.Errorf("unexpected small block details: %v", small)
+	}
+	if small["payload"] != "/wECA/8=" || small["payload_size"] != 5.0 {
+		t.Errorf("expected a base64 payload of 5 bytes, got %v (%v bytes)", small["payload"], small["payload_size"])
+	}
+
+	lazy := webBlocks[2].(map[string]interface{})
+	if _, ok := lazy["payload"]; ok {
+		t.Error("expected the large payload to be left out")
+	}
+	if lazy["payload_lazy"] != true || lazy["payload_size"] != 1102.0 {
+		t.Errorf("expected a lazy payload of 1102 bytes, got %v (%v bytes)", lazy["payload_lazy"], lazy["payload_size"])
+	}
+	if payload := tape.BlockPayload(2); len(payload) != 1102 {
+		t.Errorf("expected to fetch the 1102 byte payload, got %d bytes", len(payload))
+	}
+}
+
+// jsonKeys returns the sorted keys of a decoded JSON object.
+func jsonKeys(object map[string]interface{}) []string {
+	var keys []string
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
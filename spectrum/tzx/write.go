@@ -0,0 +1,143 @@
+package tzx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/mrcook/retroio/spectrum/tzx/blocks"
+)
+
+// blockWriter is implemented by blocks that can be written to a TZX file.
+type blockWriter interface {
+	Write(w io.Writer) error
+}
+
+// WriterOptions are used to configure how the tape is written.
+type WriterOptions struct {
+	// NormalizeVersion writes the header with the lowest TZX revision able to
+	// represent the blocks being written, as given by MinimumVersion, rather
+	// than the revision the tape was read with.
+	NormalizeVersion bool
+}
+
+// WriteOption sets one of the WriterOptions, and can be passed to SetWriterOptions.
+type WriteOption func(*WriterOptions)
+
+// WithNormalizeVersion sets the NormalizeVersion option.
+func WithNormalizeVersion() WriteOption {
+	return func(o *WriterOptions) {
+		o.NormalizeVersion = true
+	}
+}
+
+// SetWriterOptions configures how the tape is written by WriteTo,
+// ExtractBlockAsTZX, and Concat (when it is the first tape).
+func (t *TZX) SetWriterOptions(opts ...WriteOption) {
+	for _, opt := range opts {
+		opt(&t.writerOptions)
+	}
+}
+
+// WriteTo writes the tape in the TZX format; the header, followed by the
+// ArchiveInfo block (if present) and every other block, in order. It
+// returns the number of bytes written, implementing the io.WriterTo interface.
+func (t TZX) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	if err := t.writeHeader(cw, t.blocks); err != nil {
+		return cw.n, err
+	}
+	for _, block := range t.blocks {
+		if err := writeBlock(cw, block); err != nil {
+			return cw.n, err
+		}
+	}
+
+	return cw.n, nil
+}
+
+// Concat writes the tapes to the writer as a single TZX file. The header and
+// blocks of the first tape are written, then for each following tape, a
+// GlueBlock with the revision of that tape, followed by its blocks. Any
+// ArchiveInfo blocks are kept in position within their own tape. The writer
+// options of the first tape are used.
+func Concat(w io.Writer, tapes ...*TZX) error {
+	if len(tapes) == 0 {
+		return fmt.Errorf("no tapes to concatenate")
+	}
+
+	var tapeBlocks []Block
+	for i, tape := range tapes {
+		if i > 0 {
+			tapeBlocks = append(tapeBlocks, blocks.NewGlueBlock(tape.MajorVersion, tape.MinorVersion))
+		}
+		tapeBlocks = append(tapeBlocks, tape.blocks...)
+	}
+
+	if err := tapes[0].writeHeader(w, tapeBlocks); err != nil {
+		return err
+	}
+	for _, block := range tapeBlocks {
+		if err := writeBlock(w, block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExtractBlockAsTZX writes a TZX file containing only the block at the given
+// index (starting from 0, including any ArchiveInfo block), using the same
+// header revision as this tape, unless the NormalizeVersion option is set.
+func (t TZX) ExtractBlockAsTZX(index int, w io.Writer) error {
+	if index < 0 || index >= len(t.blocks) {
+		return fmt.Errorf("block index %d out of range, tape has %d blocks", index, len(t.blocks))
+	}
+
+	if err := t.writeHeader(w, t.blocks[index:index+1]); err != nil {
+		return err
+	}
+	return writeBlock(w, t.blocks[index])
+}
+
+// writeHeader writes the TZX signature and revision numbers. With the
+// NormalizeVersion option, the revision is the minimum needed by the blocks.
+func (t TZX) writeHeader(w io.Writer, tapeBlocks []Block) error {
+	h := t.header
+	if t.writerOptions.NormalizeVersion {
+		h.MajorVersion, h.MinorVersion = minimumVersion(tapeBlocks)
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return errors.Wrap(err, "unable to write TZX header")
+	}
+	return nil
+}
+
+// writeBlock writes the block in the TZX format, returning an error if the
+// block does not support writing.
+func writeBlock(w io.Writer, block Block) error {
+	writer, ok := block.(blockWriter)
+	if !ok {
+		return fmt.Errorf("writing of %s blocks is not supported", block.Name())
+	}
+	if err := writer.Write(w); err != nil {
+		return errors.Wrapf(err, "unable to write %s block", block.Name())
+	}
+	return nil
+}
+
+// countingWriter counts the number of bytes written to the writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
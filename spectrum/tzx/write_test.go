@@ -0,0 +1,210 @@
+package tzx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mrcook/retroio/storage"
+)
+
+// standardBlock is a Standard Speed Data block holding a TAP data block with
+// a flag of 0xff, three data bytes, and a valid checksum.
+var standardBlock = []byte{0x10, 0xe8, 0x03, 0x05, 0x00, 0xff, 0x01, 0x02, 0x03, 0xff}
+
+// tzxFile returns a TZX file of the given v1.x revision containing the blocks.
+func tzxFile(minorVersion uint8, rawBlocks ...[]byte) []byte {
+	raw := []byte{'Z', 'X', 'T', 'a', 'p', 'e', '!', 0x1a, 1, minorVersion}
+	for _, b := range rawBlocks {
+		raw = append(raw, b...)
+	}
+	return raw
+}
+
+// readTape reads the TZX file, failing the test on an error.
+func readTape(t *testing.T, raw []byte, opts ...Option) *TZX {
+	t.Helper()
+
+	tape := New(storage.NewReader(bytes.NewReader(raw)), opts...)
+	if err := tape.Read(); err != nil {
+		t.Fatalf("unexpected error reading the tape: %v", err)
+	}
+	return tape
+}
+
+func TestWriteToRoundTrip(t *testing.T) {
+	raw := tzxFile(20, standardBlock, []byte{0x20, 0x00, 0x00}, standardBlock)
+
+	var buf bytes.Buffer
+	if _, err := readTape(t, raw).WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("expected the written tape to match the original\nexpected: % x\ngot:      % x", raw, buf.Bytes())
+	}
+}
+
+func TestWriteToNormalizeVersion(t *testing.T) {
+	stopTape48k := []byte{0x2a, 0x00, 0x00, 0x00, 0x00}
+
+	tests := []struct {
+		name      string
+		raw       []byte
+		normalize bool
+		want      uint8
+	}{
+		{"v1.05 kept without the option", tzxFile(5, standardBlock), false, 5},
+		{"v1.05 with base blocks", tzxFile(5, standardBlock), true, 10},
+		{"v1.05 with a v1.13 block", tzxFile(5, standardBlock, stopTape48k), true, 13},
+		{"v1.20 with base blocks", tzxFile(20, standardBlock), true, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := readTape(t, tt.raw)
+			if tt.normalize {
+				tape.SetWriterOptions(WithNormalizeVersion())
+			}
+
+			var buf bytes.Buffer
+			if _, err := tape.WriteTo(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			written := buf.Bytes()
+			if written[8] != 1 || written[9] != tt.want {
+				t.Errorf("expected header v1.%02d, got v%d.%02d", tt.want, written[8], written[9])
+			}
+			if !bytes.Equal(written[10:], tt.raw[10:]) {
+				t.Error("expected the blocks to be written unchanged")
+			}
+		})
+	}
+}
+
+func TestExtractBlockAsTZX(t *testing.T) {
+	text := []byte{0x30, 0x02, 'h', 'i'}
+	tape := readTape(t, tzxFile(20, text, standardBlock, []byte{0x20, 0x00, 0x00}))
+
+	var buf bytes.Buffer
+	if err := tape.ExtractBlockAsTZX(1, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	extracted := readTape(t, buf.Bytes())
+	if len(extracted.Blocks()) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(extracted.Blocks()))
+	}
+	if !bytes.Equal(buf.Bytes(), tzxFile(20, standardBlock)) {
+		t.Errorf("expected a tape of only the standard block, got % x", buf.Bytes())
+	}
+
+	if err := tape.ExtractBlockAsTZX(3, &buf); err == nil {
+		t.Error("expected an error for a block index out of range")
+	}
+}
+
+// turboBlock returns a Turbo Speed Data block, using the ROM timings and a
+// pause of 1000ms, holding a TAP block with the flag, data and checksum.
+func turboBlock(flag uint8, data []byte, checksum uint8) []byte {
+	length := len(data) + 2
+	raw := []byte{
+		0x11,
+		0x78, 0x08, 0x9b, 0x02, 0xdf, 0x02, // pilot and sync pulses
+		0x57, 0x03, 0xae, 0x06, // zero and one bit pulses
+		0x97, 0x0c, 0x08, 0xe8, 0x03, // pilot tone, used bits, and pause
+		byte(length), byte(length >> 8), byte(length >> 16),
+		flag,
+	}
+	raw = append(raw, data...)
+	return append(raw, checksum)
+}
+
+func TestReplaceBlockData(t *testing.T) {
+	data := []byte{9, 8, 7, 6}
+	checksum := uint8(0xff ^ 9 ^ 8 ^ 7 ^ 6)
+
+	tests := []struct {
+		name     string
+		original []byte
+		want     []byte
+	}{
+		{
+			"standard speed data",
+			standardBlock,
+			[]byte{0x10, 0xe8, 0x03, 0x06, 0x00, 0xff, 9, 8, 7, 6, checksum},
+		},
+		{
+			"turbo speed data",
+			turboBlock(0xff, []byte{1, 2, 3}, 0xff),
+			turboBlock(0xff, data, checksum),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tape := readTape(t, tzxFile(20, []byte{0x20, 0x64, 0x00}, tt.original))
+			if err := tape.ReplaceBlockData(1, data); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if _, err := tape.WriteTo(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := tzxFile(20, []byte{0x20, 0x64, 0x00}, tt.want)
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("expected the new length and checksum\nexpected: % x\ngot:      % x", want, buf.Bytes())
+			}
+
+			reread := readTape(t, buf.Bytes())
+			if !dataChecksumValid(reread.Blocks()[1]) {
+				t.Error("expected a valid checksum after reading the tape back")
+			}
+		})
+	}
+}
+
+func TestReplaceBlockDataErrors(t *testing.T) {
+	tape := readTape(t, tzxFile(20, []byte{0x20, 0x64, 0x00}, standardBlock))
+
+	if err := tape.ReplaceBlockData(0, []byte{1}); err == nil {
+		t.Error("expected an error replacing the data of a pause block")
+	}
+	if err := tape.ReplaceBlockData(2, []byte{1}); err == nil {
+		t.Error("expected an error for a block index out of range")
+	}
+	if err := tape.ReplaceBlockData(1, make([]byte, 0xffff)); err == nil {
+		t.Error("expected an error for data too large for the length word")
+	}
+}
+
+func TestWriteToKeepsArchiveInfoBytes(t *testing.T) {
+	// a non-standard text type, and a block length one byte short of the text
+	archive := []byte{0x32, 0x09, 0x00, 0x02, 0x00, 0x03, 'T', 'o', 'y', 0x42, 0x02, 'h', 'i'}
+	raw := tzxFile(20, archive, standardBlock)
+
+	tape := readTape(t, raw, WithRawCapture())
+	info, ok := tape.ArchiveInfo()
+	if !ok {
+		t.Fatal("expected the tape to have archive info")
+	}
+	if !bytes.Equal(info.RawBytes, archive) {
+		t.Errorf("expected the archive info raw bytes to be % x, got % x", archive, info.RawBytes)
+	}
+
+	var buf bytes.Buffer
+	if _, err := tape.WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("expected the written tape to match the original\nexpected: % x\ngot:      % x", raw, buf.Bytes())
+	}
+
+	// without the option the block length is corrected when written
+	buf.Reset()
+	if _, err := readTape(t, raw).WriteTo(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bytes.Equal(buf.Bytes(), raw) {
+		t.Error("expected the archive info to be re-encoded without the raw bytes")
+	}
+}
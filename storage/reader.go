@@ -11,21 +11,31 @@ package storage
 import (
 	"bufio"
 	"encoding/binary"
+	"errors"
 	"io"
 	"os"
 )
 
 // Image reader, using the bufio.Reader to allow for Peeking.
 type Reader struct {
+	source io.Reader
 	reader *bufio.Reader
+	state  *readerState
 
 	Filename string
 	FileSize int
 }
 
+// readerState is shared by all copies of a Reader value.
+type readerState struct {
+	offset    int64  // number of bytes read from the start of the data
+	capturing bool   // when true, all bytes read are appended to captured
+	captured  []byte // bytes read since StartCapture was called
+}
+
 // NewReader first converts the regular reader to a buffered reader.
 func NewReader(r io.Reader) *Reader {
-	return &Reader{reader: bufio.NewReader(r)}
+	return &Reader{source: r, reader: bufio.NewReader(r), state: &readerState{}}
 }
 
 // NewReaderFromFile opens the given filename and creates a new reader.
@@ -46,17 +56,26 @@ func NewReaderFromFile(file *os.File) (*Reader, error) {
 // It will read either the currently buffered bytes, or perform a io.ReadFull.
 func (r Reader) Read(b []byte) (int, error) {
 	// if the buffer contains enough bytes, use them.
+	var n int
+	var err error
+
 	if len(b) <= r.reader.Buffered() {
-		return r.reader.Read(b)
+		n, err = r.reader.Read(b)
+	} else {
+		n, err = io.ReadFull(r.reader, b)
 	}
+	r.advance(b[:n])
 
-	return io.ReadFull(r.reader, b)
+	return n, err
 }
 
 // ReadByte delegates to the underlying Reader function, and reads a single byte.
 // Errors are discarded so this should only be used when a byte is known to be present.
 func (r Reader) ReadByte() byte {
-	b, _ := r.reader.ReadByte()
+	b, err := r.reader.ReadByte()
+	if err == nil {
+		r.advance([]byte{b})
+	}
 	return b
 }
 
@@ -109,9 +128,83 @@ func (r Reader) PeekShort() (uint16, error) {
 	return binary.LittleEndian.Uint16(b[:]), nil
 }
 
-// Discard delegates to the underlying Reader function.
+// Discard delegates to the underlying Reader function. When capturing,
+// the bytes are read instead so they are included in the capture.
 func (r Reader) Discard(n int) (int, error) {
-	return r.reader.Discard(n)
+	if r.state != nil && r.state.capturing {
+		return r.Read(make([]byte, n))
+	}
+	discarded, err := r.reader.Discard(n)
+	if r.state != nil {
+		r.state.offset += int64(discarded)
+	}
+	return discarded, err
+}
+
+// Offset returns the number of bytes that have been read (or discarded)
+// from the start of the data.
+func (r Reader) Offset() int64 {
+	if r.state == nil {
+		return 0
+	}
+	return r.state.offset
+}
+
+// CanSeek reports whether the underlying reader is an io.Seeker.
+func (r Reader) CanSeek() bool {
+	_, ok := r.source.(io.Seeker)
+	return ok
+}
+
+// SeekTo moves the reader to the given offset from the start of the underlying
+// reader, discarding any buffered data. An error is returned when the
+// underlying reader is not an io.Seeker.
+func (r Reader) SeekTo(offset int64) error {
+	seeker, ok := r.source.(io.Seeker)
+	if !ok {
+		return errors.New("reader does not support seeking")
+	}
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.reader.Reset(r.source)
+	if r.state != nil {
+		r.state.offset = offset
+	}
+	return nil
+}
+
+// StartCapture begins recording a copy of all bytes read from this point,
+// discarding anything from a previous capture.
+func (r Reader) StartCapture() {
+	if r.state != nil {
+		r.state.capturing = true
+		r.state.captured = nil
+	}
+}
+
+// StopCapture ends the recording and returns the bytes read since
+// StartCapture was called.
+func (r Reader) StopCapture() []byte {
+	if r.state == nil {
+		return nil
+	}
+	captured := r.state.captured
+	r.state.capturing = false
+	r.state.captured = nil
+	return captured
+}
+
+// advance moves the read offset forward past the given bytes, recording
+// them when capturing.
+func (r Reader) advance(b []byte) {
+	if r.state == nil {
+		return
+	}
+	r.state.offset += int64(len(b))
+	if r.state.capturing {
+		r.state.captured = append(r.state.captured, b...)
+	}
 }
 
 // BytesToLong converts a slice of 4 little endian ordered bytes to uint32.